@@ -0,0 +1,177 @@
+package animals_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/coinbase/fuzzy-trie/pkg/levenshtein"
+	"github.com/coinbase/fuzzy-trie/pkg/trie"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The number of times each animal is repeated, suffixed with a number, giving a tree of 99,000 names
+const animalRepeatCount = 200
+
+var animalTree *trie.Tree[*animal]
+
+// getAnimalTree loads the animals used by the tests of the trie package, each repeated with a numeric suffix. Unlike the
+// benchmarks of the single package, this needs no data to be downloaded.
+func getAnimalTree() *trie.Tree[*animal] {
+	if animalTree != nil {
+		return animalTree
+	}
+
+	animalsText, err := os.ReadFile("../../../../pkg/trie/animals.txt")
+	if err != nil {
+		panic(fmt.Sprintf("failed to read animals: %v", err))
+	}
+
+	var animals []*animal
+	for i := 0; i < animalRepeatCount; i++ {
+		for _, name := range strings.Split(strings.TrimSpace(string(animalsText)), "\n") {
+			animals = append(animals, &animal{name: fmt.Sprintf("%s %d", name, i)})
+		}
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelFn()
+
+	animalTree, err = trie.LoadTree(ctx, animals, func(_ context.Context, item *animal) (string, error) {
+		return item.name, nil
+	})
+	if err != nil {
+		panic(fmt.Sprintf("failed to load animal tree: %v", err))
+	}
+	return animalTree
+}
+
+// Tests that search for terms of different lengths without a maximum distance, measuring every matching key term in full
+func BenchmarkAnimalsE(b *testing.B) {
+	benchmarkAnimals("e", nil, trie.SearchOptions{}, b)
+}
+
+func BenchmarkAnimalsDomestic(b *testing.B) {
+	benchmarkAnimals("domestic", nil, trie.SearchOptions{}, b)
+}
+
+func BenchmarkAnimalsBactrianCamel(b *testing.B) {
+	benchmarkAnimals("bactrian camel", nil, trie.SearchOptions{}, b)
+}
+
+// The same as the tests without a maximum distance, but measuring key terms through a plain distance function that fills
+// the distance matrix, rather than with the bit-parallel algorithm of the Levenshtein metric
+func BenchmarkAnimalsFullMatrixE(b *testing.B) {
+	benchmarkAnimals("e", trie.DistanceFunc(levenshtein.LevenshteinDistance), trie.SearchOptions{}, b)
+}
+
+func BenchmarkAnimalsFullMatrixDomestic(b *testing.B) {
+	benchmarkAnimals("domestic", trie.DistanceFunc(levenshtein.LevenshteinDistance), trie.SearchOptions{}, b)
+}
+
+func BenchmarkAnimalsFullMatrixBactrianCamel(b *testing.B) {
+	benchmarkAnimals("bactrian camel", trie.DistanceFunc(levenshtein.LevenshteinDistance), trie.SearchOptions{}, b)
+}
+
+// Tests that search with a maximum distance, which the Levenshtein metric uses to stop measuring distant key terms early
+func BenchmarkAnimalsBoundedE(b *testing.B) {
+	benchmarkAnimals("e", nil, trie.SearchOptions{MaxDistance: getMaxDistance(10)}, b)
+}
+
+func BenchmarkAnimalsBoundedDomestic(b *testing.B) {
+	benchmarkAnimals("domestic", nil, trie.SearchOptions{MaxDistance: getMaxDistance(10)}, b)
+}
+
+func BenchmarkAnimalsBoundedBactrianCamel(b *testing.B) {
+	benchmarkAnimals("bactrian camel", nil, trie.SearchOptions{MaxDistance: getMaxDistance(10)}, b)
+}
+
+// The same as the bounded tests, but measuring every key term in full through a plain distance function
+func BenchmarkAnimalsDistanceFuncE(b *testing.B) {
+	benchmarkAnimals("e", trie.DistanceFunc(levenshtein.LevenshteinDistance), trie.SearchOptions{MaxDistance: getMaxDistance(10)}, b)
+}
+
+func BenchmarkAnimalsDistanceFuncDomestic(b *testing.B) {
+	benchmarkAnimals("domestic", trie.DistanceFunc(levenshtein.LevenshteinDistance), trie.SearchOptions{MaxDistance: getMaxDistance(10)}, b)
+}
+
+func BenchmarkAnimalsDistanceFuncBactrianCamel(b *testing.B) {
+	benchmarkAnimals("bactrian camel", trie.DistanceFunc(levenshtein.LevenshteinDistance), trie.SearchOptions{MaxDistance: getMaxDistance(10)}, b)
+}
+
+// Tests that compare walking up the tree from every leaf with walking down it from the root with a maximum distance of 2.
+// Walking up matches only the key terms containing the characters of the search term in order, so it returns fewer
+// results than walking down, which matches every key term within the maximum distance.
+func BenchmarkAnimalsLeafUpWildcat(b *testing.B) {
+	benchmarkAnimals("wildcat 1", nil, trie.SearchOptions{MaxDistance: getMaxDistance(2)}, b)
+}
+
+func BenchmarkAnimalsLeafUpWildcatTypo(b *testing.B) {
+	benchmarkAnimals("wildact 12", nil, trie.SearchOptions{MaxDistance: getMaxDistance(2)}, b)
+}
+
+func BenchmarkAnimalsTopDownWildcat(b *testing.B) {
+	benchmarkAnimals("wildcat 1", nil, trie.SearchOptions{MaxDistance: getMaxDistance(2), MatchMode: trie.MatchModeFuzzy}, b)
+}
+
+func BenchmarkAnimalsTopDownWildcatTypo(b *testing.B) {
+	benchmarkAnimals("wildact 12", nil, trie.SearchOptions{MaxDistance: getMaxDistance(2), MatchMode: trie.MatchModeFuzzy}, b)
+}
+
+// The same as the top-down tests, returning the same results, but through a plain distance function that does not bound
+// the Levenshtein distance, so that no branch is abandoned and every key term is measured
+func BenchmarkAnimalsTopDownUnprunedWildcat(b *testing.B) {
+	benchmarkAnimals("wildcat 1", trie.DistanceFunc(levenshtein.LevenshteinDistance), trie.SearchOptions{MaxDistance: getMaxDistance(2), MatchMode: trie.MatchModeFuzzy}, b)
+}
+
+func BenchmarkAnimalsTopDownUnprunedWildcatTypo(b *testing.B) {
+	benchmarkAnimals("wildact 12", trie.DistanceFunc(levenshtein.LevenshteinDistance), trie.SearchOptions{MaxDistance: getMaxDistance(2), MatchMode: trie.MatchModeFuzzy}, b)
+}
+
+func getMaxDistance(maxDistance int) *int {
+	return &maxDistance
+}
+
+func benchmarkAnimals(searchPhrase string, metric trie.Metric, options trie.SearchOptions, b *testing.B) {
+	ctx, cancelFn := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelFn()
+
+	distanceTree := trie.NewDistanceTrees([]*trie.Tree[*animal]{getAnimalTree()})
+	if metric != nil {
+		distanceTree.SetMetric(metric)
+	}
+
+	var results []*trie.DistanceResult[*animal]
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var searchErr error
+		results, searchErr = distanceTree.SearchWithOptions(ctx, searchPhrase, options)
+		if searchErr != nil {
+			panic(fmt.Sprintf("failed to search: %v", searchErr))
+		}
+	}
+
+	b.StopTimer()
+
+	b.ReportMetric(float64(len(results)), "search_results")
+}
+
+type animal struct {
+	name string
+}
+
+func (a *animal) GetPrimaryDistanceFactor() *float64 {
+	return nil
+}
+
+func (a *animal) GetSecondaryDistances() []*int {
+	return nil
+}
+
+func (a *animal) SortingGroup() int {
+	return 1
+}
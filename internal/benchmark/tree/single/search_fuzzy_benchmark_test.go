@@ -0,0 +1,54 @@
+package single_test
+
+import (
+	"github.com/coinbase/fuzzy-trie/pkg/levenshtein"
+	"github.com/coinbase/fuzzy-trie/pkg/trie"
+	"testing"
+)
+
+// Tests that compare walking up the tree from every leaf with walking down it from the root, pruning distant branches.
+// Walking up matches only the key terms containing the characters of the search term in order, so it returns fewer
+// results than walking down, which matches every key term within the maximum distance.
+func BenchmarkFuzzyLeafUp100000(b *testing.B) {
+	benchmarkMultiTreeFuzzy(100_000, trie.LevenshteinMetric, trie.MatchModeSubsequence, b)
+}
+
+func BenchmarkFuzzyLeafUp1000000(b *testing.B) {
+	benchmarkMultiTreeFuzzy(1_000_000, trie.LevenshteinMetric, trie.MatchModeSubsequence, b)
+}
+
+func BenchmarkFuzzyLeafUp3000000(b *testing.B) {
+	benchmarkMultiTreeFuzzy(3_000_000, trie.LevenshteinMetric, trie.MatchModeSubsequence, b)
+}
+
+func BenchmarkFuzzyTopDown100000(b *testing.B) {
+	benchmarkMultiTreeFuzzy(100_000, trie.LevenshteinMetric, trie.MatchModeFuzzy, b)
+}
+
+func BenchmarkFuzzyTopDown1000000(b *testing.B) {
+	benchmarkMultiTreeFuzzy(1_000_000, trie.LevenshteinMetric, trie.MatchModeFuzzy, b)
+}
+
+func BenchmarkFuzzyTopDown3000000(b *testing.B) {
+	benchmarkMultiTreeFuzzy(3_000_000, trie.LevenshteinMetric, trie.MatchModeFuzzy, b)
+}
+
+// The same as the top-down tests, returning the same results, but through a plain distance function that does not bound
+// the Levenshtein distance, so that no branch is abandoned and every key term is measured
+func BenchmarkFuzzyTopDownUnpruned100000(b *testing.B) {
+	benchmarkMultiTreeFuzzy(100_000, trie.DistanceFunc(levenshtein.LevenshteinDistance), trie.MatchModeFuzzy, b)
+}
+
+func BenchmarkFuzzyTopDownUnpruned1000000(b *testing.B) {
+	benchmarkMultiTreeFuzzy(1_000_000, trie.DistanceFunc(levenshtein.LevenshteinDistance), trie.MatchModeFuzzy, b)
+}
+
+func BenchmarkFuzzyTopDownUnpruned3000000(b *testing.B) {
+	benchmarkMultiTreeFuzzy(3_000_000, trie.DistanceFunc(levenshtein.LevenshteinDistance), trie.MatchModeFuzzy, b)
+}
+
+func benchmarkMultiTreeFuzzy(dataCount int, metric trie.Metric, matchMode trie.MatchMode, b *testing.B) {
+	// The top-down search can only prune branches with a maximum distance in effect
+	maxDistance := 2
+	benchmarkMultiTreeSearch(dataCount, multiCharPhrase, metric, trie.SearchOptions{MaxDistance: &maxDistance, MatchMode: matchMode}, b)
+}
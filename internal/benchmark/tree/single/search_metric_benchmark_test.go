@@ -0,0 +1,91 @@
+package single_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/coinbase/fuzzy-trie/pkg/levenshtein"
+	"github.com/coinbase/fuzzy-trie/pkg/trie"
+	"testing"
+	"time"
+)
+
+// Tests that compare the cost of the different metrics on a multi-character search phrase
+func BenchmarkMetricLevenshtein100000(b *testing.B) {
+	benchmarkMultiTreeMetric(100_000, multiCharPhrase, trie.LevenshteinMetric, b)
+}
+
+func BenchmarkMetricLevenshtein1000000(b *testing.B) {
+	benchmarkMultiTreeMetric(1_000_000, multiCharPhrase, trie.LevenshteinMetric, b)
+}
+
+func BenchmarkMetricDamerauLevenshtein100000(b *testing.B) {
+	benchmarkMultiTreeMetric(100_000, multiCharPhrase, trie.DamerauLevenshteinMetric, b)
+}
+
+func BenchmarkMetricDamerauLevenshtein1000000(b *testing.B) {
+	benchmarkMultiTreeMetric(1_000_000, multiCharPhrase, trie.DamerauLevenshteinMetric, b)
+}
+
+func BenchmarkMetricJaroWinkler100000(b *testing.B) {
+	benchmarkMultiTreeMetric(100_000, multiCharPhrase, trie.JaroWinklerMetric, b)
+}
+
+func BenchmarkMetricJaroWinkler1000000(b *testing.B) {
+	benchmarkMultiTreeMetric(1_000_000, multiCharPhrase, trie.JaroWinklerMetric, b)
+}
+
+// The same as the Levenshtein metric, but without the ability to skip key terms based on their lengths
+func BenchmarkMetricDistanceFunc100000(b *testing.B) {
+	benchmarkMultiTreeMetric(100_000, multiCharPhrase, trie.DistanceFunc(levenshtein.LevenshteinDistance), b)
+}
+
+func BenchmarkMetricDistanceFunc1000000(b *testing.B) {
+	benchmarkMultiTreeMetric(1_000_000, multiCharPhrase, trie.DistanceFunc(levenshtein.LevenshteinDistance), b)
+}
+
+func benchmarkMultiTreeMetric(dataCount int, searchPhrase string, metric trie.Metric, b *testing.B) {
+	// Restrict the search so that the metrics' ability to skip distant key terms is exercised
+	maxDistance := 10
+	benchmarkMultiTreeSearch(dataCount, searchPhrase, metric, trie.SearchOptions{MaxDistance: &maxDistance}, b)
+}
+
+// benchmarkMultiTreeSearch searches the developer and project name trees with the given metric and options.
+func benchmarkMultiTreeSearch(dataCount int, searchPhrase string, metric trie.Metric, options trie.SearchOptions, b *testing.B) {
+	ctx, cancelFn := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelFn()
+
+	dataSubset := getTestData()[:dataCount]
+
+	developerNameTree, err := loadDeveloperNameTree(ctx, dataSubset)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load developer name tree: %v", err))
+	}
+
+	projectNameTree, err := loadProjectNameTree(ctx, dataSubset)
+	if err != nil {
+		panic(fmt.Sprintf("failed to load project name tree: %v", err))
+	}
+
+	chanTimer := NewChanTimer(ctx)
+
+	distanceTree := trie.NewDistanceTrees([]*trie.Tree[*testDatum]{developerNameTree, projectNameTree})
+	distanceTree.SetTimer(chanTimer)
+	distanceTree.SetMetric(metric)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	results, searchErr := distanceTree.SearchWithOptions(ctx, searchPhrase, options)
+	if searchErr != nil {
+		panic(fmt.Sprintf("failed to search: %v", searchErr))
+	}
+
+	b.StopTimer()
+
+	chanTimer.Collect()
+
+	b.ReportMetric(float64(len(results)), "search_results")
+	b.ReportMetric(float64(chanTimer.GetNodeSearchIterationCount()), "search_count_total")
+	b.ReportMetric(float64(chanTimer.GetTotalNodeSearchIterationDuration().Milliseconds()), "search_node_duration_total_millis")
+	b.ReportMetric(float64(chanTimer.GetTotalTreeSearchDuration().Milliseconds()), "search_tree_duration_total_millis")
+}
@@ -116,6 +116,7 @@ func benchmarkMultiTree(dataCount int, searchPhrase string, b *testing.B) {
 	traceStop := beginTrace(traceWriter)
 	defer traceStop()
 
+	b.ReportAllocs()
 	b.ResetTimer()
 
 	results, searchErr := distanceTree.Search(ctx, searchPhrase)
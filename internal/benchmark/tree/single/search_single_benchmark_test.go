@@ -53,6 +53,7 @@ func benchmarkSearchSingleTree(dataCount int, b *testing.B) {
 	traceStop := beginTrace(traceWriter)
 	defer traceStop()
 
+	b.ReportAllocs()
 	b.ResetTimer()
 
 	results, searchErr := distanceTree.Search(ctx, "e")
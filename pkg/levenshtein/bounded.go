@@ -0,0 +1,80 @@
+package levenshtein
+
+// BoundedDistance measures the Levenshtein distance between two strings in the same manner as LevenshteinDistance,
+// but only as far as is needed to determine whether it is no more than the given maximum distance. If it is, the
+// distance is returned along with true; otherwise, maxDistance+1 (or 0, for a negative maxDistance) is returned along
+// with false.
+//
+// This implements Ukkonen's cut-off: only the cells within maxDistance of the diagonal of the distance matrix can
+// lie on an alignment within maxDistance edits, so only those are calculated, and the calculation stops as soon as
+// every cell in a column exceeds maxDistance. This takes O(k·max(m,n)) time for a maximum distance of k, rather
+// than O(m·n), and O(min(m,n)) space.
+func BoundedDistance(s, t string, maxDistance int) (int, bool) {
+	if maxDistance < 0 {
+		return 0, false
+	}
+
+	r1, r2 := []rune(s), []rune(t)
+	if len(r1) > len(r2) {
+		r1, r2 = r2, r1
+	}
+
+	// The distance is never less than the difference in the lengths of the strings
+	if len(r2)-len(r1) > maxDistance {
+		return maxDistance + 1, false
+	}
+
+	// Nor is it ever more than the length of the longer string, so a larger band would not exclude any cell
+	band := maxDistance
+	if band > len(r2) {
+		band = len(r2)
+	}
+
+	column := make([]int, len(r1)+1)
+	for y := range column {
+		column[y] = y
+	}
+
+	for x := 1; x <= len(r2); x++ {
+		lowY := max(1, x-band)
+		highY := len(r1)
+		if x+band < highY {
+			highY = x + band
+		}
+
+		// The cell above the band is never on an alignment within the band, so it is treated as exceeding it
+		lastDiag := column[lowY-1]
+		if lowY == 1 {
+			column[0] = x
+		} else {
+			column[lowY-1] = band + 1
+		}
+
+		columnMin := column[lowY-1]
+		for y := lowY; y <= highY; y++ {
+			oldDiag := column[y]
+			cost := 0
+			if r1[y-1] != r2[x-1] {
+				cost = 1
+			}
+			// Beside the band's last cell, the previous column's cell was outside that column's band and was never
+			// calculated, but its initial value already exceeds the maximum distance
+			column[y] = min(column[y]+1, column[y-1]+1, lastDiag+cost)
+			lastDiag = oldDiag
+
+			if column[y] < columnMin {
+				columnMin = column[y]
+			}
+		}
+
+		// Every alignment passes through this column, so none can be within the maximum distance once it is exceeded
+		if columnMin > maxDistance {
+			return maxDistance + 1, false
+		}
+	}
+
+	if distance := column[len(r1)]; distance <= maxDistance {
+		return distance, true
+	}
+	return maxDistance + 1, false
+}
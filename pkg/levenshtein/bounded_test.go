@@ -0,0 +1,68 @@
+package levenshtein_test
+
+import (
+	"github.com/coinbase/fuzzy-trie/pkg/levenshtein"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"math/rand"
+	"testing"
+)
+
+var _ = Describe("BoundedDistance", func() {
+	DescribeTable("measures the distance between two strings up to a maximum",
+		func(s string, t string, maxDistance int, expectedDistance int, expectedWithin bool) {
+			distance, isWithin := levenshtein.BoundedDistance(s, t, maxDistance)
+			Expect(isWithin).To(Equal(expectedWithin), "whether the distance is within the maximum should be correct")
+			Expect(distance).To(Equal(expectedDistance), "the distance should be correct")
+
+			distance, isWithin = levenshtein.BoundedDistance(t, s, maxDistance)
+			Expect(isWithin).To(Equal(expectedWithin), "whether the distance is within the maximum should be symmetric")
+			Expect(distance).To(Equal(expectedDistance), "the distance should be symmetric")
+		},
+		Entry("identical strings", "BITCOIN", "BITCOIN", 0, 0, true),
+		Entry("empty strings", "", "", 0, 0, true),
+		Entry("one empty string within the maximum", "", "ETH", 3, 3, true),
+		Entry("one empty string beyond the maximum", "", "ETH", 2, 3, false),
+		Entry("a distance equal to the maximum", "KITTEN", "SITTING", 3, 3, true),
+		Entry("a distance beyond the maximum", "KITTEN", "SITTING", 2, 3, false),
+		Entry("a maximum beyond the lengths of the strings", "BTC", "ETH", 100, 2, true),
+		Entry("lengths too different for the maximum", "BTC", "BITCOIN", 3, 4, false),
+		Entry("a distance only found off the diagonal", "ABCDEF", "XABCDE", 2, 2, true),
+		Entry("an early exit", "AAAAAAAA", "BBBBBBBB", 1, 2, false),
+		Entry("multi-byte characters", "Straße", "Strasse", 2, 2, true),
+		Entry("a negative maximum", "BTC", "BTC", -1, 0, false),
+	)
+
+	It("should agree with LevenshteinDistance", func() {
+		random := rand.New(rand.NewSource(1))
+		randomString := func() string {
+			runes := make([]rune, random.Intn(12))
+			for i := range runes {
+				// A small alphabet makes close strings likely
+				runes[i] = []rune("ABCé")[random.Intn(4)]
+			}
+			return string(runes)
+		}
+
+		for i := 0; i < 5000; i++ {
+			s, t, maxDistance := randomString(), randomString(), random.Intn(8)
+			expectedDistance := levenshtein.LevenshteinDistance(s, t)
+
+			distance, isWithin := levenshtein.BoundedDistance(s, t, maxDistance)
+			if expectedDistance <= maxDistance {
+				Expect(isWithin).To(BeTrue(), "'%s' and '%s' should be within %d", s, t, maxDistance)
+				Expect(distance).To(Equal(expectedDistance), "the distance between '%s' and '%s' should be correct", s, t)
+			} else {
+				Expect(isWithin).To(BeFalse(), "'%s' and '%s' should not be within %d", s, t, maxDistance)
+				Expect(distance).To(Equal(maxDistance+1), "the distance between '%s' and '%s' should be past the maximum", s, t)
+			}
+		}
+	})
+})
+
+func BenchmarkBoundedDistance(b *testing.B) {
+	benchmarkDistance(func(s, t string) int {
+		distance, _ := levenshtein.BoundedDistance(s, t, 10)
+		return distance
+	}, b)
+}
@@ -0,0 +1,126 @@
+package levenshtein
+
+// Costs configures the cost of each kind of edit counted by Costs.Distance, allowing some edits to be treated as more
+// likely typos than others.
+type Costs struct {
+	// Insertion is the cost of inserting a character into the first string.
+	Insertion float64
+	// Deletion is the cost of deleting a character from the first string.
+	Deletion float64
+	// Substitution is the cost of substituting a character of the first string with a different character, unless the
+	// SubstitutionTable gives a cost for the pair.
+	Substitution float64
+	// Transposition is the cost of transposing two adjacent characters, in the manner of DamerauLevenshteinDistance,
+	// or zero if a transposition is instead counted as the edits it is made up of.
+	Transposition float64
+	// SubstitutionTable, if set, gives the costs of substituting particular pairs of characters.
+	SubstitutionTable SubstitutionTable
+}
+
+// SubstitutionTable gives the costs of substituting particular pairs of characters, such as those commonly mistyped for
+// each other. Implementations must be safe for concurrent use.
+type SubstitutionTable interface {
+	// SubstitutionCost gets the cost of substituting the given character with the given different character, returning
+	// false if the table gives no cost for the pair.
+	SubstitutionCost(from rune, to rune) (float64, bool)
+}
+
+// MinCostSubstitutionTable is a SubstitutionTable whose costs are never less than a known minimum cost.
+type MinCostSubstitutionTable interface {
+	SubstitutionTable
+	// MinSubstitutionCost gets the lowest cost the table gives for substituting any pair of characters.
+	MinSubstitutionCost() float64
+}
+
+var (
+	// UnitCosts counts every insertion, deletion and substitution as a single edit, measuring Levenshtein distances.
+	UnitCosts = Costs{Insertion: 1, Deletion: 1, Substitution: 1}
+
+	// TypoCosts counts every edit as a single edit, including the transposition of two adjacent characters, except for
+	// the substitution of characters on adjacent keys of a QWERTY keyboard, which counts as half an edit.
+	TypoCosts = Costs{Insertion: 1, Deletion: 1, Substitution: 1, Transposition: 1, SubstitutionTable: QWERTYAdjacency}
+)
+
+// Distance measures the lowest total cost of the edits required to change the first string into the second.
+// Like DamerauLevenshteinDistance, no substring is edited more than once when transpositions are counted.
+//
+// This uses O(min(m,n)) space if the insertion and deletion costs are equal and there is no substitution table, and
+// O(n) space otherwise, for a second string of n characters.
+func (c Costs) Distance(s, t string) float64 {
+	r1, r2 := []rune(s), []rune(t)
+	// The rows are kept as short as possible, unless that would change the costs of the edits by reversing them
+	if len(r1) > len(r2) && c.Insertion == c.Deletion && c.SubstitutionTable == nil {
+		r1, r2 = r2, r1
+	}
+
+	// Only the current row and the two before it are needed to detect transpositions
+	previousPreviousRow := make([]float64, len(r2)+1)
+	previousRow := make([]float64, len(r2)+1)
+	currentRow := make([]float64, len(r2)+1)
+
+	for x := 1; x <= len(r2); x++ {
+		previousRow[x] = previousRow[x-1] + c.Insertion
+	}
+
+	for y := 1; y <= len(r1); y++ {
+		currentRow[0] = previousRow[0] + c.Deletion
+
+		for x := 1; x <= len(r2); x++ {
+			substitution := previousRow[x-1]
+			if r1[y-1] != r2[x-1] {
+				substitution += c.substitutionCost(r1[y-1], r2[x-1])
+			}
+			currentRow[x] = minFloat(previousRow[x]+c.Deletion, currentRow[x-1]+c.Insertion, substitution)
+
+			if c.Transposition > 0 && x > 1 && y > 1 && r1[y-1] == r2[x-2] && r1[y-2] == r2[x-1] {
+				if transposition := previousPreviousRow[x-2] + c.Transposition; transposition < currentRow[x] {
+					currentRow[x] = transposition
+				}
+			}
+		}
+
+		previousPreviousRow, previousRow, currentRow = previousRow, currentRow, previousPreviousRow
+	}
+
+	return previousRow[len(r2)]
+}
+
+// MinEditCost gets the lowest cost of changing a string by a single Levenshtein edit: an insertion, a deletion, a
+// substitution or, as a transposition is two such edits, half a transposition. Any two strings with a Levenshtein distance
+// of d are then at least d times this cost apart. Zero is returned if the lowest cost is not known, as is the case for
+// a SubstitutionTable that does not implement MinCostSubstitutionTable.
+func (c Costs) MinEditCost() float64 {
+	minCost := minFloat(c.Insertion, c.Deletion, c.Substitution)
+	if c.Transposition > 0 && c.Transposition/2 < minCost {
+		minCost = c.Transposition / 2
+	}
+	if c.SubstitutionTable != nil {
+		minCostTable, isMinCost := c.SubstitutionTable.(MinCostSubstitutionTable)
+		if !isMinCost {
+			return 0
+		}
+		if tableCost := minCostTable.MinSubstitutionCost(); tableCost < minCost {
+			minCost = tableCost
+		}
+	}
+	return max(minCost, 0)
+}
+
+// substitutionCost gets the cost of substituting the given character with the given different character.
+func (c Costs) substitutionCost(from rune, to rune) float64 {
+	if c.SubstitutionTable != nil {
+		if cost, hasCost := c.SubstitutionTable.SubstitutionCost(from, to); hasCost {
+			return cost
+		}
+	}
+	return c.Substitution
+}
+
+func minFloat(a, b, c float64) float64 {
+	if a < b && a < c {
+		return a
+	} else if b < c {
+		return b
+	}
+	return c
+}
@@ -0,0 +1,92 @@
+package levenshtein_test
+
+import (
+	"github.com/coinbase/fuzzy-trie/pkg/levenshtein"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"math/rand"
+)
+
+var _ = Describe("Costs", func() {
+	DescribeTable("measures the cost of the edits between two strings",
+		func(costs levenshtein.Costs, s string, t string, expectedDistance float64) {
+			Expect(costs.Distance(s, t)).To(BeNumerically("~", expectedDistance), "the distance should be correct")
+		},
+		Entry("identical strings", levenshtein.TypoCosts, "BTC", "BTC", 0.0),
+		Entry("empty strings", levenshtein.TypoCosts, "", "", 0.0),
+		Entry("a typo on an adjacent key", levenshtein.TypoCosts, "BTC", "BTV", 0.5),
+		Entry("a typo on a distant key", levenshtein.TypoCosts, "BTC", "BTZ", 1.0),
+		Entry("a typo on an adjacent key in lower case", levenshtein.TypoCosts, "btc", "btv", 0.5),
+		Entry("a typo on an adjacent key between rows", levenshtein.TypoCosts, "ETH", "DTH", 0.5),
+		Entry("a transposition", levenshtein.TypoCosts, "BTC", "TBC", 1.0),
+		Entry("a transposition without a transposition cost", levenshtein.UnitCosts, "BTC", "TBC", 2.0),
+		Entry("an insertion", levenshtein.Costs{Insertion: 1, Deletion: 2, Substitution: 3}, "BT", "BTC", 1.0),
+		Entry("a deletion", levenshtein.Costs{Insertion: 1, Deletion: 2, Substitution: 3}, "BTC", "BT", 2.0),
+		Entry("a substitution costing more than an insertion and a deletion", levenshtein.Costs{Insertion: 1, Deletion: 2, Substitution: 5}, "BTC", "BTX", 3.0),
+		Entry("multi-byte characters", levenshtein.UnitCosts, "Straße", "Strasse", 2.0),
+	)
+
+	DescribeTable("gets the lowest cost of a single Levenshtein edit",
+		func(costs levenshtein.Costs, expectedCost float64) {
+			Expect(costs.MinEditCost()).To(BeNumerically("~", expectedCost), "the cost should be correct")
+		},
+		Entry("unit costs", levenshtein.UnitCosts, 1.0),
+		Entry("typo costs", levenshtein.TypoCosts, 0.5),
+		Entry("a cheap insertion", levenshtein.Costs{Insertion: 0.25, Deletion: 1, Substitution: 1}, 0.25),
+		Entry("a cheap transposition", levenshtein.Costs{Insertion: 1, Deletion: 1, Substitution: 1, Transposition: 0.5}, 0.25),
+		Entry("a substitution table without a minimum cost", levenshtein.Costs{Insertion: 1, Deletion: 1, Substitution: 1, SubstitutionTable: substitutionTableFunc(nil)}, 0.0),
+	)
+
+	It("should measure Levenshtein and Damerau-Levenshtein distances with unit costs", func() {
+		random := rand.New(rand.NewSource(1))
+		randomString := func() string {
+			runes := make([]rune, random.Intn(10))
+			for i := range runes {
+				runes[i] = []rune("ABCé")[random.Intn(4)]
+			}
+			return string(runes)
+		}
+
+		damerauCosts := levenshtein.UnitCosts
+		damerauCosts.Transposition = 1
+		for i := 0; i < 2000; i++ {
+			s, t := randomString(), randomString()
+			Expect(levenshtein.UnitCosts.Distance(s, t)).To(BeNumerically("==", levenshtein.LevenshteinDistance(s, t)),
+				"the distance between '%s' and '%s' should be the Levenshtein distance", s, t)
+			Expect(damerauCosts.Distance(s, t)).To(BeNumerically("==", levenshtein.DamerauLevenshteinDistance(s, t)),
+				"the distance between '%s' and '%s' should be the Damerau-Levenshtein distance", s, t)
+		}
+	})
+})
+
+var _ = Describe("KeyboardAdjacency", func() {
+	DescribeTable("gives the cost of substituting adjacent keys",
+		func(from rune, to rune, expectedAdjacent bool) {
+			cost, isAdjacent := levenshtein.QWERTYAdjacency.SubstitutionCost(from, to)
+			Expect(isAdjacent).To(Equal(expectedAdjacent), "the adjacency of the keys should be correct")
+			if expectedAdjacent {
+				Expect(cost).To(BeNumerically("~", 0.5), "the cost should be correct")
+			}
+
+			_, isAdjacent = levenshtein.QWERTYAdjacency.SubstitutionCost(to, from)
+			Expect(isAdjacent).To(Equal(expectedAdjacent), "the adjacency of the keys should be symmetric")
+		},
+		Entry("beside", 'S', 'D', true),
+		Entry("above", 'S', 'W', true),
+		Entry("above and to the right", 'S', 'E', true),
+		Entry("below", 'S', 'X', true),
+		Entry("below and to the left", 'S', 'Z', true),
+		Entry("above and to the left", 'S', 'Q', false),
+		Entry("below and to the right", 'S', 'C', false),
+		Entry("in different cases", 's', 'D', true),
+		Entry("distant", 'C', 'Z', false),
+		Entry("not on the keyboard", 'S', 'ß', false),
+	)
+})
+
+// substitutionTableFunc is a function implementation of the SubstitutionTable interface.
+type substitutionTableFunc func(from rune, to rune) (float64, bool)
+
+func (f substitutionTableFunc) SubstitutionCost(from rune, to rune) (float64, bool) {
+	return f(from, to)
+}
@@ -0,0 +1,45 @@
+package levenshtein
+
+// DamerauLevenshteinDistance measures the difference between two strings in the same manner as LevenshteinDistance,
+// but also counts the transposition of two adjacent characters as a single edit (e.g., "BTC" and "TBC" are a
+// distance of 1 apart, rather than 2).
+//
+// This implements the optimal string alignment variant of the Damerau-Levenshtein distance, in which no substring
+// is edited more than once, using O(min(m,n)) space.
+func DamerauLevenshteinDistance(s, t string) int {
+	r1, r2 := []rune(s), []rune(t)
+	if len(r1) > len(r2) {
+		r1, r2 = r2, r1
+	}
+
+	// Only the current row and the two before it are needed to detect transpositions
+	previousPreviousRow := make([]int, len(r1)+1)
+	previousRow := make([]int, len(r1)+1)
+	currentRow := make([]int, len(r1)+1)
+
+	for y := 0; y <= len(r1); y++ {
+		previousRow[y] = y
+	}
+
+	for x := 1; x <= len(r2); x++ {
+		currentRow[0] = x
+
+		for y := 1; y <= len(r1); y++ {
+			cost := 0
+			if r1[y-1] != r2[x-1] {
+				cost = 1
+			}
+			currentRow[y] = min(previousRow[y]+1, currentRow[y-1]+1, previousRow[y-1]+cost)
+
+			if x > 1 && y > 1 && r1[y-1] == r2[x-2] && r1[y-2] == r2[x-1] {
+				if transposition := previousPreviousRow[y-2] + 1; transposition < currentRow[y] {
+					currentRow[y] = transposition
+				}
+			}
+		}
+
+		previousPreviousRow, previousRow, currentRow = previousRow, currentRow, previousPreviousRow
+	}
+
+	return previousRow[len(r1)]
+}
@@ -0,0 +1,32 @@
+package levenshtein_test
+
+import (
+	"github.com/coinbase/fuzzy-trie/pkg/levenshtein"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DamerauLevenshteinDistance", func() {
+	DescribeTable("measures the distance between two strings",
+		func(s string, t string, expectedDistance int, expectedLevenshteinDistance int) {
+			Expect(levenshtein.DamerauLevenshteinDistance(s, t)).To(Equal(expectedDistance), "the distance should be correct")
+			Expect(levenshtein.DamerauLevenshteinDistance(t, s)).To(Equal(expectedDistance), "the distance should be symmetric")
+			Expect(levenshtein.LevenshteinDistance(s, t)).To(Equal(expectedLevenshteinDistance), "the Levenshtein distance should be correct")
+		},
+		Entry("identical strings", "BTC", "BTC", 0, 0),
+		Entry("empty strings", "", "", 0, 0),
+		Entry("one empty string", "", "ETH", 3, 3),
+		Entry("a leading transposition", "BTC", "TBC", 1, 2),
+		Entry("a trailing transposition", "BTC", "BCT", 1, 2),
+		Entry("multiple transpositions", "ABCD", "BADC", 2, 3),
+		Entry("a transposition with a substitution", "BTC", "TBX", 2, 3),
+		Entry("a substitution", "BTC", "BTX", 1, 1),
+		Entry("an insertion", "BTC", "BTCX", 1, 1),
+		Entry("a deletion", "BTC", "BC", 1, 1),
+		Entry("no transposed substring edited twice", "CA", "ABC", 3, 3),
+		Entry("a transposition of accented characters", "Café", "Caéf", 1, 2),
+		Entry("a transposition of non-Latin characters", "ビットコイン", "ビトッコイン", 1, 2),
+		Entry("a transposition of emoji", "🚀🌕", "🌕🚀", 1, 2),
+		Entry("multi-byte substitutions", "Straße", "Strasse", 2, 2),
+	)
+})
@@ -0,0 +1,74 @@
+package levenshtein
+
+import "unicode"
+
+// KeyboardAdjacency is a SubstitutionTable giving a reduced cost to substituting characters on adjacent keys of a
+// keyboard, which are commonly mistyped for each other. Letters are compared regardless of their case.
+type KeyboardAdjacency struct {
+	adjacentKeys map[keyPair]struct{}
+	cost         float64
+}
+
+// keyPair is a pair of characters on the keys of a keyboard, in upper case.
+type keyPair struct {
+	from rune
+	to   rune
+}
+
+// QWERTYLayout is the layout of the character keys of a QWERTY keyboard, from the top row to the bottom row.
+var QWERTYLayout = []string{"1234567890", "QWERTYUIOP", "ASDFGHJKL", "ZXCVBNM"}
+
+// QWERTYAdjacency substitutes the characters on adjacent keys of a QWERTY keyboard (e.g., "C" and "V") at a cost of half
+// an edit.
+var QWERTYAdjacency = NewKeyboardAdjacency(QWERTYLayout, 0.5)
+
+// NewKeyboardAdjacency builds a KeyboardAdjacency for the given layout, giving the characters on the keys of each row
+// from the top row to the bottom row, substituting the characters on adjacent keys at the given cost.
+// Each row is assumed to be offset to the right of the row above it, as on a typical keyboard, so that a key is adjacent
+// to the keys beside it, the key above it and the key above and to the right, and the key below it and the key below
+// and to the left (e.g., "S" is adjacent to "A", "D", "W", "E", "Z" and "X" on a QWERTY keyboard).
+func NewKeyboardAdjacency(layout []string, cost float64) *KeyboardAdjacency {
+	keyboard := &KeyboardAdjacency{
+		adjacentKeys: make(map[keyPair]struct{}),
+		cost:         cost,
+	}
+
+	rows := make([][]rune, len(layout))
+	for rowIndex, row := range layout {
+		rows[rowIndex] = []rune(row)
+	}
+
+	addAdjacentKeys := func(key rune, row []rune, column int) {
+		if column >= 0 && column < len(row) {
+			adjacentKey := unicode.ToUpper(row[column])
+			keyboard.adjacentKeys[keyPair{from: key, to: adjacentKey}] = struct{}{}
+			keyboard.adjacentKeys[keyPair{from: adjacentKey, to: key}] = struct{}{}
+		}
+	}
+
+	// Only the keys beside and below each key are added, as the pairs are added in both directions
+	for rowIndex, row := range rows {
+		for column, key := range row {
+			key = unicode.ToUpper(key)
+			addAdjacentKeys(key, row, column+1)
+			if rowIndex+1 < len(rows) {
+				addAdjacentKeys(key, rows[rowIndex+1], column-1)
+				addAdjacentKeys(key, rows[rowIndex+1], column)
+			}
+		}
+	}
+
+	return keyboard
+}
+
+func (k *KeyboardAdjacency) SubstitutionCost(from rune, to rune) (float64, bool) {
+	if _, isAdjacent := k.adjacentKeys[keyPair{from: unicode.ToUpper(from), to: unicode.ToUpper(to)}]; isAdjacent {
+		return k.cost, true
+	}
+	return 0, false
+}
+
+// MinSubstitutionCost gets the cost of substituting the characters on adjacent keys, which is the only cost given.
+func (k *KeyboardAdjacency) MinSubstitutionCost() float64 {
+	return k.cost
+}
@@ -9,7 +9,11 @@ package levenshtein
 // optimized C version found here:
 // http://en.wikibooks.org/wiki/Algorithm_implementation/Strings/Levenshtein_distance#C
 func LevenshteinDistance(s, t string) int {
-	r1, r2 := []rune(s), []rune(t)
+	return runeDistance([]rune(s), []rune(t))
+}
+
+// runeDistance measures the Levenshtein distance between two strings of runes in the manner of LevenshteinDistance.
+func runeDistance(r1, r2 []rune) int {
 	column := make([]int, 1, 64)
 
 	for y := 1; y <= len(r1); y++ {
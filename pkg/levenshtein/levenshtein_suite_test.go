@@ -0,0 +1,13 @@
+package levenshtein_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLevenshtein(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Levenshtein Suite")
+}
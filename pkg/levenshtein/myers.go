@@ -0,0 +1,150 @@
+package levenshtein
+
+// MyersDistance measures the Levenshtein distance between two strings in the same manner as LevenshteinDistance, but
+// using the bit-parallel algorithm of Myers, as adapted to edit distances by Hyyrö, whenever the shorter string has no
+// more than 64 runes. Each column of the distance matrix is then represented by the differences between its adjacent
+// cells, held in a pair of 64-bit words, and calculated from the previous column in a constant number of operations
+// rather than one per rune. Longer strings fall back to LevenshteinDistance.
+//
+// Strings consisting only of ASCII characters are measured byte by byte, without being converted to runes.
+func MyersDistance(s, t string) int {
+	if isASCII(s) && isASCII(t) {
+		if len(s) > len(t) {
+			s, t = t, s
+		}
+		if len(s) > myersMaxPatternLength {
+			return LevenshteinDistance(s, t)
+		}
+		return asciiMyersDistance(s, t)
+	}
+
+	r1, r2 := []rune(s), []rune(t)
+	if len(r1) > len(r2) {
+		r1, r2 = r2, r1
+	}
+	if len(r1) > myersMaxPatternLength {
+		return runeDistance(r1, r2)
+	}
+	return runeMyersDistance(r1, r2)
+}
+
+// myersMaxPatternLength is the largest number of runes in the shorter of two strings measured by MyersDistance, being
+// the number of bits in each of the words representing a column of the distance matrix.
+const myersMaxPatternLength = 64
+
+// myersColumn holds a column of the distance matrix of a pattern of no more than 64 characters against a prefix of a
+// text, as the vertical differences between its adjacent cells: bit i of positive is set if the cell for the first
+// i+1 characters of the pattern is one more than the cell above it, and bit i of negative if it is one less.
+type myersColumn struct {
+	positive uint64
+	negative uint64
+	// lastBit is the bit of the pattern's last character
+	lastBit uint64
+	// distance is the distance of the whole pattern from the prefix of the text
+	distance int
+}
+
+// newMyersColumn builds the first column of the distance matrix of a pattern of the given length, in which each cell is
+// one more than the cell above it.
+func newMyersColumn(patternLength int) myersColumn {
+	return myersColumn{
+		positive: ^uint64(0),
+		lastBit:  1 << (patternLength - 1),
+		distance: patternLength,
+	}
+}
+
+// advance calculates the next column of the distance matrix, for the next character of the text, given the bits of the
+// characters of the pattern matching it.
+func (c *myersColumn) advance(matchBits uint64) {
+	verticalChanges := matchBits | c.negative
+	horizontalChanges := (((matchBits & c.positive) + c.positive) ^ c.positive) | matchBits
+	horizontalPositive := c.negative | ^(horizontalChanges | c.positive)
+	horizontalNegative := c.positive & horizontalChanges
+
+	if horizontalPositive&c.lastBit != 0 {
+		c.distance++
+	} else if horizontalNegative&c.lastBit != 0 {
+		c.distance--
+	}
+
+	// The cells of the first row are each one more than the cell before them
+	horizontalPositive = horizontalPositive<<1 | 1
+	horizontalNegative <<= 1
+	c.positive = horizontalNegative | ^(verticalChanges | horizontalPositive)
+	c.negative = horizontalPositive & verticalChanges
+}
+
+// asciiMyersDistance measures the distance between the given ASCII pattern of no more than 64 characters and the given
+// ASCII text.
+func asciiMyersDistance(pattern, text string) int {
+	if len(pattern) == 0 {
+		return len(text)
+	}
+
+	var matchBits [128]uint64
+	for i := 0; i < len(pattern); i++ {
+		matchBits[pattern[i]] |= 1 << i
+	}
+
+	column := newMyersColumn(len(pattern))
+	for i := 0; i < len(text); i++ {
+		column.advance(matchBits[text[i]])
+	}
+	return column.distance
+}
+
+// runeMyersDistance measures the distance between the given pattern of no more than 64 runes and the given text.
+func runeMyersDistance(pattern, text []rune) int {
+	if len(pattern) == 0 {
+		return len(text)
+	}
+
+	// The bits of the pattern's Latin-1 runes are looked up directly, and those of any others are searched for
+	var latinMatchBits [256]uint64
+	var otherRunes []rune
+	var otherMatchBits []uint64
+	for i, patternRune := range pattern {
+		if patternRune < 256 {
+			latinMatchBits[patternRune] |= 1 << i
+			continue
+		}
+
+		otherIndex := 0
+		for otherIndex < len(otherRunes) && otherRunes[otherIndex] != patternRune {
+			otherIndex++
+		}
+		if otherIndex == len(otherRunes) {
+			otherRunes = append(otherRunes, patternRune)
+			otherMatchBits = append(otherMatchBits, 0)
+		}
+		otherMatchBits[otherIndex] |= 1 << i
+	}
+
+	column := newMyersColumn(len(pattern))
+	for _, textRune := range text {
+		var matchBits uint64
+		if textRune < 256 {
+			matchBits = latinMatchBits[textRune]
+		} else {
+			for otherIndex, otherRune := range otherRunes {
+				if otherRune == textRune {
+					matchBits = otherMatchBits[otherIndex]
+					break
+				}
+			}
+		}
+		column.advance(matchBits)
+	}
+	return column.distance
+}
+
+// isASCII determines if the given string consists only of ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package levenshtein_test
+
+import (
+	"github.com/coinbase/fuzzy-trie/pkg/levenshtein"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+var _ = Describe("MyersDistance", func() {
+	DescribeTable("measures the distance between two strings",
+		func(s string, t string, expectedDistance int) {
+			Expect(levenshtein.MyersDistance(s, t)).To(Equal(expectedDistance), "the distance should be correct")
+			Expect(levenshtein.MyersDistance(t, s)).To(Equal(expectedDistance), "the distance should be symmetric")
+			Expect(levenshtein.LevenshteinDistance(s, t)).To(Equal(expectedDistance), "the distance should agree with LevenshteinDistance")
+		},
+		Entry("identical strings", "BITCOIN", "BITCOIN", 0),
+		Entry("empty strings", "", "", 0),
+		Entry("one empty string", "", "ETH", 3),
+		Entry("substitutions, insertions and deletions", "KITTEN", "SITTING", 3),
+		Entry("no characters in common", "ABC", "XYZ", 3),
+		Entry("a pattern of 64 characters", strings.Repeat("AB", 32), strings.Repeat("BA", 33), 2),
+		Entry("a pattern of more than 64 characters", strings.Repeat("AB", 33), strings.Repeat("BA", 34), 2),
+		Entry("Latin-1 characters", "Café", "Cafe", 1),
+		Entry("non-Latin characters", "ビットコイン", "ビトッコイン", 2),
+		Entry("mixed scripts", "BTC ビットコイン", "BTC ビットコ", 2),
+		Entry("non-Latin characters beyond 64 runes", strings.Repeat("ビット", 22), strings.Repeat("ビッド", 22), 22),
+		Entry("emoji", "🚀🌕", "🌕🚀", 2),
+	)
+
+	It("should agree with LevenshteinDistance on either side of the longest pattern", func() {
+		random := rand.New(rand.NewSource(1))
+		randomString := func(alphabet []rune) string {
+			runes := make([]rune, random.Intn(80))
+			for i := range runes {
+				runes[i] = alphabet[random.Intn(len(alphabet))]
+			}
+			return string(runes)
+		}
+
+		for _, alphabet := range []string{"AB", "ABCD", "ABé", "ABビ"} {
+			for i := 0; i < 1000; i++ {
+				s, t := randomString([]rune(alphabet)), randomString([]rune(alphabet))
+				Expect(levenshtein.MyersDistance(s, t)).To(Equal(levenshtein.LevenshteinDistance(s, t)),
+					"the distance between '%s' and '%s' should agree", s, t)
+			}
+		}
+	})
+})
+
+func FuzzMyersDistance(f *testing.F) {
+	f.Add("KITTEN", "SITTING")
+	f.Add("", "ETH")
+	f.Add("Straße", "Strasse")
+	f.Add("ビットコイン", "ビトッコイン")
+	f.Add(strings.Repeat("AB", 32), strings.Repeat("BA", 40))
+	f.Add(strings.Repeat("ABC", 30), "CAB")
+
+	f.Fuzz(func(t *testing.T, s string, u string) {
+		if distance, expectedDistance := levenshtein.MyersDistance(s, u), levenshtein.LevenshteinDistance(s, u); distance != expectedDistance {
+			t.Errorf("the distance between %q and %q should be %d, not %d", s, u, expectedDistance, distance)
+		}
+	})
+}
+
+func BenchmarkLevenshteinDistance(b *testing.B) {
+	benchmarkDistance(levenshtein.LevenshteinDistance, b)
+}
+
+func BenchmarkMyersDistance(b *testing.B) {
+	benchmarkDistance(levenshtein.MyersDistance, b)
+}
+
+func benchmarkDistance(distanceFunc func(s, t string) int, b *testing.B) {
+	pairs := [][2]string{
+		{"DOMESTIC", "DOMESTIC BACTRIAN CAMEL"},
+		{"BACTRIAN CAMEL", "AMERICAN BLACK BEAR"},
+		{"CAFÉ", "CAFE AU LAIT"},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, pair := range pairs {
+			distanceFunc(pair[0], pair[1])
+		}
+	}
+}
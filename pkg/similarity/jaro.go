@@ -0,0 +1,78 @@
+package similarity
+
+// jaroWinklerPrefixLength is the largest number of leading characters that JaroWinkler gives credit for sharing.
+const jaroWinklerPrefixLength = 4
+
+// jaroWinklerPrefixScale is the proportion of the remaining dissimilarity that JaroWinkler removes for each leading
+// character shared.
+const jaroWinklerPrefixScale = 0.1
+
+// Jaro measures the Jaro similarity of two strings, from 0 for strings with no characters in common to 1 for identical
+// strings. Two characters match if they are the same and no further apart in the two strings than half the length of
+// the longer string, less one; the similarity is then the average of the proportions of each string's characters that
+// match and of the matching characters that are in the same order.
+func Jaro(s, t string) float64 {
+	r1, r2 := []rune(s), []rune(t)
+	if len(r1) == 0 && len(r2) == 0 {
+		return 1
+	} else if len(r1) == 0 || len(r2) == 0 {
+		return 0
+	}
+
+	matchDistance := max(len(r1), len(r2))/2 - 1
+	if matchDistance < 0 {
+		matchDistance = 0
+	}
+
+	isMatched1 := make([]bool, len(r1))
+	isMatched2 := make([]bool, len(r2))
+	matches := 0
+	for i, r := range r1 {
+		for j := max(0, i-matchDistance); j <= min(len(r2)-1, i+matchDistance); j++ {
+			if !isMatched2[j] && r2[j] == r {
+				isMatched1[i], isMatched2[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+
+	if matches == 0 {
+		return 0
+	}
+
+	// Count the matching characters that are out of order, each transposition counting twice
+	outOfOrder := 0
+	j := 0
+	for i, r := range r1 {
+		if !isMatched1[i] {
+			continue
+		}
+		for !isMatched2[j] {
+			j++
+		}
+		if r != r2[j] {
+			outOfOrder++
+		}
+		j++
+	}
+
+	matchCount := float64(matches)
+	transpositions := float64(outOfOrder) / 2
+	return (matchCount/float64(len(r1)) + matchCount/float64(len(r2)) + (matchCount-transpositions)/matchCount) / 3
+}
+
+// JaroWinkler measures the Jaro-Winkler similarity of two strings, from 0 to 1 in the same manner as Jaro, but giving
+// additional credit to strings sharing their first few characters, as typos are less common at the start of a word.
+func JaroWinkler(s, t string) float64 {
+	jaro := Jaro(s, t)
+
+	prefixLength := 0
+	for r1, r2 := []rune(s), []rune(t); prefixLength < min(len(r1), len(r2), jaroWinklerPrefixLength); prefixLength++ {
+		if r1[prefixLength] != r2[prefixLength] {
+			break
+		}
+	}
+
+	return jaro + float64(prefixLength)*jaroWinklerPrefixScale*(1-jaro)
+}
@@ -0,0 +1,25 @@
+package similarity
+
+import (
+	"github.com/coinbase/fuzzy-trie/pkg/levenshtein"
+	"unicode/utf8"
+)
+
+// NormalizedLevenshtein measures the similarity of two strings from their Levenshtein distance, from 0 for strings
+// requiring every character of the longer string to be edited to 1 for identical strings, as described by
+// NormalizeDistance. Unlike the distance itself, this is comparable between pairs of strings of different lengths.
+func NormalizedLevenshtein(s, t string) float64 {
+	return NormalizeDistance(float64(levenshtein.MyersDistance(s, t)), utf8.RuneCountInString(s), utf8.RuneCountInString(t))
+}
+
+// NormalizeDistance converts the given edit distance between two strings of the given lengths, in characters, into a
+// similarity from 0 to 1: the proportion of the characters of the longer string that the distance leaves unedited.
+// Two empty strings are identical, with a similarity of 1, and distances greater than the length of the longer string
+// have a similarity of 0.
+func NormalizeDistance(distance float64, length1 int, length2 int) float64 {
+	longerLength := max(length1, length2)
+	if longerLength == 0 {
+		return 1
+	}
+	return min(max(1-distance/float64(longerLength), 0), 1)
+}
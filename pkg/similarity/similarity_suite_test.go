@@ -0,0 +1,13 @@
+package similarity_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSimilarity(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Similarity Suite")
+}
@@ -0,0 +1,51 @@
+package similarity_test
+
+import (
+	"github.com/coinbase/fuzzy-trie/pkg/similarity"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Similarity", func() {
+	DescribeTable("measures the Jaro and Jaro-Winkler similarities of two strings",
+		func(s string, t string, expectedJaro float64, expectedJaroWinkler float64) {
+			Expect(similarity.Jaro(s, t)).To(BeNumerically("~", expectedJaro, 0.001), "the Jaro similarity should be correct")
+			Expect(similarity.Jaro(t, s)).To(BeNumerically("~", expectedJaro, 0.001), "the Jaro similarity should be symmetric")
+			Expect(similarity.JaroWinkler(s, t)).To(BeNumerically("~", expectedJaroWinkler, 0.001), "the Jaro-Winkler similarity should be correct")
+			Expect(similarity.JaroWinkler(t, s)).To(BeNumerically("~", expectedJaroWinkler, 0.001), "the Jaro-Winkler similarity should be symmetric")
+		},
+		Entry("identical strings", "BITCOIN", "BITCOIN", 1.0, 1.0),
+		Entry("empty strings", "", "", 1.0, 1.0),
+		Entry("one empty string", "", "ETH", 0.0, 0.0),
+		Entry("a character in common", "BTC", "ETH", 0.555, 0.555),
+		Entry("nothing in common", "ABC", "XYZ", 0.0, 0.0),
+		Entry("a transposition", "MARTHA", "MARHTA", 0.944, 0.961),
+		Entry("a substitution and a deletion", "DWAYNE", "DUANE", 0.822, 0.84),
+		Entry("strings of different lengths", "DIXON", "DICKSONX", 0.767, 0.813),
+		Entry("multi-byte characters", "ビットコイン", "ビトッコイン", 0.944, 0.950),
+	)
+
+	DescribeTable("measures the normalized Levenshtein similarity of two strings",
+		func(s string, t string, expectedSimilarity float64) {
+			Expect(similarity.NormalizedLevenshtein(s, t)).To(BeNumerically("~", expectedSimilarity, 0.001), "the similarity should be correct")
+			Expect(similarity.NormalizedLevenshtein(t, s)).To(BeNumerically("~", expectedSimilarity, 0.001), "the similarity should be symmetric")
+		},
+		Entry("identical strings", "BITCOIN", "BITCOIN", 1.0),
+		Entry("empty strings", "", "", 1.0),
+		Entry("one empty string", "", "ETH", 0.0),
+		Entry("a short string with a typo", "BTC", "BTV", 0.667),
+		Entry("a long string with a typo", "DOMESTIC BACTRIAN CAMEL", "DOMESTIC BACTRIAN CAMEK", 0.957),
+		Entry("multi-byte characters", "Straße", "Strasse", 0.714),
+	)
+
+	DescribeTable("normalizes distances",
+		func(distance float64, length1 int, length2 int, expectedSimilarity float64) {
+			Expect(similarity.NormalizeDistance(distance, length1, length2)).To(BeNumerically("~", expectedSimilarity), "the similarity should be correct")
+		},
+		Entry("no distance", 0.0, 3, 3, 1.0),
+		Entry("a fractional distance", 0.5, 2, 4, 0.875),
+		Entry("a distance beyond the longer length", 5.0, 2, 4, 0.0),
+		Entry("a negative distance", -1.0, 2, 4, 1.0),
+		Entry("empty strings", 0.0, 0, 0, 1.0),
+	)
+})
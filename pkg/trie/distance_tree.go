@@ -1,11 +1,9 @@
 package trie
 
 import (
+	"container/heap"
 	"context"
 	"fmt"
-	"github.com/coinbase/fuzzy-trie/pkg/levenshtein"
-	"math"
-	"sort"
 	"time"
 )
 
@@ -21,45 +19,192 @@ type ComparableFuzzable interface {
 // one weighed lower in priority to the previous one.
 // This allows, for example, the fuzzy searching of assets by their symbol and then by their name,
 // weighing name as lower in priority of a match than the symbol.
+// Alternatively, see NewDistanceTreesWithWeights to control how much each tree contributes to the ranking of results.
 type DistanceTrees[T ComparableFuzzable] struct {
-	trees []*Tree[T]
+	trees []*WeightedTree[T]
 	timer Timer
+	// rankByScore determines if results are ranked by their Score ahead of their distances
+	rankByScore bool
+	// metric measures the distance between the search term and the key terms of matching nodes in trees without their own Metric
+	metric Metric
 }
 
 // DistanceResult is a result of a fuzzy search, containing the result and the distance from the search term.
 type DistanceResult[T any] struct {
+	// Distances holds the distance of the result from the search term in each tree, in the order of the trees, followed
+	// by the result's secondary distances. A distance is nil if the result did not match the search term in that tree.
+	// For a DistanceTrees instance built with NewDistanceTrees, the distance in the tree at index i is offset by 10^i.
 	Distances []*int
-	Result    T
+	// Score is the combined score of the result across all the trees, where a higher score denotes a closer match.
+	// It is the sum of each matching tree's weight multiplied by the score of the result's distance in that tree.
+	Score float64
+	// Matches holds how the result matched the search term in each tree, in the order of the trees.
+	// A match is nil if the result did not match the search term in that tree.
+	Matches []*TreeMatch
+	// TermMatches holds, for searches splitting the search term into multiple query terms with a QueryOperator, how the
+	// result matched each query term in each tree, in the order of the query terms and then of the trees. The matches
+	// for a query term are nil if the result did not match it in any tree. Matches then holds the closest of these in
+	// each tree.
+	TermMatches [][]*TreeMatch
+	// Similarity is the similarity of the result to the search term, from 0 to 1, being the highest Similarity of its
+	// Matches. Unlike its distances, this is comparable between results with short and long key terms, so that a
+	// single cutoff can be applied to all results. For searches with multiple query terms, the similarity in each tree is
+	// the average of the similarities of the query terms' matches in it, with unmatched query terms counting as 0.
+	Similarity float64
+	// Explanation is a breakdown of how the result was scored and ranked; it is only populated if SearchOptions.Explain is set.
+	Explanation *Explanation
+	Result      T
+	// sortingGroup is the sorting group of the result, captured once for purposes of ranking
+	sortingGroup int
+	// treeDistances holds the unrounded distances of the result in each tree, where it has a non-nil distance
+	treeDistances []float64
+	// rawTreeDistances holds the combined distances of the query terms in each tree before they were scaled by the
+	// result's primary distance factor; it is only populated for searches with multiple query terms
+	rawTreeDistances []float64
+	// valueIndices holds the index of the result among the values of the node of its match in each tree, which breaks
+	// ties between results matched under the same key term
+	valueIndices []int
+	// termValueIndices holds the valueIndices of each query term's matches; it is only populated for searches with
+	// multiple query terms
+	termValueIndices [][]int
+	// heapIndex is the index of the result within the resultHeap holding it, if any
+	heapIndex int
 }
 
+// newDistanceResult builds a DistanceResult for the given item, not yet matched in any tree, capturing its secondary
+// distances and sorting group so that it can be ranked as soon as it is matched.
+func (wt *DistanceTrees[T]) newDistanceResult(item T) *DistanceResult[T] {
+	return &DistanceResult[T]{
+		Result:        item,
+		Distances:     append(make([]*int, len(wt.trees)+1), item.GetSecondaryDistances()...),
+		Matches:       make([]*TreeMatch, len(wt.trees)),
+		sortingGroup:  item.SortingGroup(),
+		treeDistances: make([]float64, len(wt.trees)),
+		valueIndices:  make([]int, len(wt.trees)),
+	}
+}
+
+// NewDistanceTrees builds a DistanceTrees instance that ranks results by their distance in each of the given trees in turn,
+// only considering a tree's distances when the results are equally distant in all the trees before it.
+// The distance of a result in the tree at index i is offset by 10^i in its Distances.
 func NewDistanceTrees[T ComparableFuzzable](trees []*Tree[T]) *DistanceTrees[T] {
+	weightedTrees := make([]*WeightedTree[T], len(trees))
+	for treeIndex, tree := range trees {
+		weightedTrees[treeIndex] = &WeightedTree[T]{
+			Tree:           tree,
+			Weight:         getDefaultWeight(treeIndex),
+			Scorer:         InverseDistanceScorer,
+			distanceOffset: getDefaultDistanceOffset(treeIndex),
+		}
+	}
+
 	return &DistanceTrees[T]{
-		trees: trees,
-		timer: defaultTimer,
+		trees:  weightedTrees,
+		timer:  defaultTimer,
+		metric: LevenshteinMetric,
 	}
 }
 
 // Search searches the trees within this DistanceTrees instance for the given search term, returning
-// results with their primary and secondary distances
+// results with their primary and secondary distances.
+// Key terms are matched against the search term as normalized by each tree's Normalizer, but their distances are
+// measured from the search term as given, so, e.g., "cat" is at a distance of 3 from an item loaded as "Cat".
+// Use SearchWithOptions to measure distances from the normalized search term instead.
 func (wt *DistanceTrees[T]) Search(ctx context.Context, searchTerm string) ([]*DistanceResult[T], error) {
-	results := make(map[T]*DistanceResult[T])
+	return wt.SearchWithOptions(ctx, searchTerm, SearchOptions{measuresSearchTermAsGiven: true})
+}
 
-	for treeIndex := range wt.trees {
-		if searchErr := wt.searchTree(ctx, treeIndex, searchTerm, results); searchErr != nil {
-			return nil, fmt.Errorf("faield to search tree at index %d: %w", treeIndex, searchErr)
-		}
+// SearchWithOptions searches the trees within this DistanceTrees instance for the given search term, restricting
+// and shaping the results according to the given options.
+// Unlike Search, distances are measured from the search term as normalized by each tree's Normalizer, so, e.g., "cat"
+// is at a distance of 0 from an item loaded as "Cat".
+func (wt *DistanceTrees[T]) SearchWithOptions(ctx context.Context, searchTerm string, options SearchOptions) ([]*DistanceResult[T], error) {
+	if err := options.validate(); err != nil {
+		return nil, err
 	}
 
-	var weightedResults []*DistanceResult[T]
-	for item, weightedResult := range results {
-		// Append the secondary distances, too
-		weightedResult.Distances = append(weightedResult.Distances, item.GetSecondaryDistances()...)
+	results, err := wt.searchQuery(ctx, searchTerm, options)
+	if err != nil {
+		return nil, err
+	}
+
+	weightedResults := make([]*DistanceResult[T], 0, len(results))
+	for _, weightedResult := range results {
+		weightedResult.Score = wt.scoreResult(weightedResult)
+		weightedResult.Similarity = weightedResult.getSimilarity()
+		if options.Explain {
+			weightedResult.Explanation = wt.explainResult(weightedResult, weightedResult.Distances[len(wt.trees)+1:])
+		}
 		weightedResults = append(weightedResults, weightedResult)
 	}
 
-	wt.sortResults(ctx, weightedResults)
+	return wt.rankResults(ctx, weightedResults, options), nil
+}
+
+// scoreResult calculates the combined Score of the given result across the trees it has matched in so far.
+func (wt *DistanceTrees[T]) scoreResult(result *DistanceResult[T]) float64 {
+	score := 0.0
+	for treeIndex, weightedTree := range wt.trees {
+		if result.Distances[treeIndex] != nil {
+			score += weightedTree.score(result.treeDistances[treeIndex])
+		}
+	}
+	return score
+}
+
+// getSimilarity gets the highest similarity of this result to the search term across the trees it matched in.
+func (r *DistanceResult[T]) getSimilarity() float64 {
+	highestSimilarity := 0.0
+	for treeIndex, treeMatch := range r.Matches {
+		if treeMatch == nil {
+			continue
+		}
+
+		treeSimilarity := treeMatch.Similarity
+		if r.TermMatches != nil {
+			treeSimilarity = 0
+			for _, termMatches := range r.TermMatches {
+				if termMatches != nil && termMatches[treeIndex] != nil {
+					treeSimilarity += termMatches[treeIndex].Similarity
+				}
+			}
+			treeSimilarity /= float64(len(r.TermMatches))
+		}
+		highestSimilarity = max(highestSimilarity, treeSimilarity)
+	}
+	return highestSimilarity
+}
+
+// searchQuery searches the trees for the given search term, splitting it into query terms if the options require it,
+// returning the unranked results.
+func (wt *DistanceTrees[T]) searchQuery(ctx context.Context, searchTerm string, options SearchOptions) (map[T]*DistanceResult[T], error) {
+	queryTerms := parseQuery(searchTerm, options)
+	if len(queryTerms) > 1 {
+		return wt.searchQueryTerms(ctx, queryTerms, options)
+	}
+
+	state := &searchState[T]{
+		// A search term split into a single query term is searched for without the characters around it
+		searchTerm: queryTerms[0],
+		options:    options,
+		results:    make(map[T]*DistanceResult[T]),
+	}
+	if wt.isBoundedDuringSearch(options) {
+		comparator := wt.getResultComparator()
+		if options.GroupOrdering != GroupOrderingIgnored {
+			comparator = groupResultComparator(comparator)
+		}
+		state.topResults = &resultHeap[T]{comparator: comparator}
+		state.resultLimit = options.Offset + options.Limit
+	}
+
+	for treeIndex := range wt.trees {
+		if searchErr := wt.searchTree(ctx, treeIndex, state); searchErr != nil {
+			return nil, fmt.Errorf("faield to search tree at index %d: %w", treeIndex, searchErr)
+		}
+	}
 
-	return weightedResults, nil
+	return state.results, nil
 }
 
 // SetTimer sets the Timer implementation to be used by this tree to measure its behavior
@@ -67,14 +212,107 @@ func (wt *DistanceTrees[T]) SetTimer(timer Timer) {
 	wt.timer = timer
 }
 
+// SetDistanceFunc sets the function used to measure the distance between the search term and the key terms of the
+// trees; by default, this is levenshtein.LevenshteinDistance.
+// This is equivalent to SetMetric with the given function. As a function cannot declare that it counts transpositions,
+// use SetMetric with DamerauLevenshteinMetric rather than levenshtein.DamerauLevenshteinDistance to match key terms
+// with transposed characters (see TranspositionMetric).
+func (wt *DistanceTrees[T]) SetDistanceFunc(distanceFunc DistanceFunc) {
+	wt.SetMetric(distanceFunc)
+}
+
+// SetMetric sets the Metric used to measure the distance between the search term and the key terms of the trees that
+// have not been given a Metric of their own; by default, this is LevenshteinMetric.
+func (wt *DistanceTrees[T]) SetMetric(metric Metric) {
+	wt.metric = metric
+}
+
+// searchState holds the state of a single search across the trees of a DistanceTrees instance.
+type searchState[T comparable] struct {
+	searchTerm string
+	options    SearchOptions
+	results    map[T]*DistanceResult[T]
+	// topResults, if set, holds the best results found so far, up to resultLimit of them, and results holds no others
+	topResults  *resultHeap[T]
+	resultLimit int
+}
+
+// isBoundedDuringSearch determines if only the results needed to fill the page requested by the given options can be
+// kept while the trees are being searched, rather than once every result has been found.
+// This requires a result to be ranked by its match in a single tree, so that its rank is known as soon as it is matched,
+// and the results not to be capped or interleaved by sorting group.
+func (wt *DistanceTrees[T]) isBoundedDuringSearch(options SearchOptions) bool {
+	return options.Limit > 0 &&
+		len(wt.trees) == 1 &&
+		options.GroupOrdering != GroupOrderingInterleaved &&
+		options.MaxResultsPerGroup <= 0
+}
+
+// recordResult records the given result, which has just been matched, or matched more closely than before, in the
+// given state's results. If the state bounds its results, the result is only kept if it ranks among the best results
+// found so far, displacing the worst of them if there are already enough.
+// Because a result only ever ranks better when it is matched again, a displaced result can never be needed again.
+func (wt *DistanceTrees[T]) recordResult(state *searchState[T], result *DistanceResult[T], isRecorded bool) {
+	if state.topResults == nil {
+		if !isRecorded {
+			state.results[result.Result] = result
+		}
+		return
+	}
+
+	result.Score = wt.scoreResult(result)
+	if isRecorded {
+		heap.Fix(state.topResults, result.heapIndex)
+		return
+	}
+
+	if state.topResults.Len() < state.resultLimit {
+		heap.Push(state.topResults, result)
+	} else if state.topResults.comparator(result, state.topResults.results[0]) < 0 {
+		delete(state.results, state.topResults.results[0].Result)
+		state.topResults.replaceWorst(result)
+	} else {
+		return
+	}
+	state.results[result.Result] = result
+}
+
+// treeSearch holds the state of a single search of one tree within a DistanceTrees instance.
+type treeSearch struct {
+	treeIndex int
+	// query is the search term, prepared for the tree
+	query *PreparedQuery
+	// measuredSearchTerm is the term from which the distances of key terms are measured: the normalized search term,
+	// unless the search measures distances from the search term as given
+	measuredSearchTerm string
+	// matchOffsets receives the offsets of the search runes from the end of each evaluated node's key term
+	matchOffsets []int
+	// maxDistance is the largest distance a key term may be from the search term to be matched, or -1 if there is no limit
+	maxDistance int
+	metric      Metric
+	// isLengthBounded determines if the metric's distances are bounded by the difference in the lengths of the terms
+	isLengthBounded bool
+	// isLevenshtein determines if the metric measures Levenshtein distances, which may then be calculated incrementally
+	isLevenshtein bool
+	// boundedMetric is the metric, if it can stop measuring a key term at the maximum distance and one is in effect
+	boundedMetric BoundedMetric
+	// transpositionStates is scratch space for matching key terms with transposed runes in MatchModeSubsequence, if the
+	// metric counts transpositions
+	transpositionStates []transpositionState
+	// tokenPositionPenalty is the distance added for each token preceding a key term if the tree was loaded with a Tokenizer
+	tokenPositionPenalty float64
+	matchMode            MatchMode
+	// prefixEditBudget is the largest number of edits allowed between the search term and a prefix of a key term when
+	// matching with MatchModeFuzzyPrefix
+	prefixEditBudget int
+}
+
 // evaluate evaluates the given node against the given search term and, if applicable, calculates the weight for the given tree index.
 // It returns a slice of the subsequent nodes, if any, to be examined.
 func (wt *DistanceTrees[T]) evaluate(
 	ctx context.Context,
-	searchTerm string,
-	treeIndex int,
-	treeCount int,
-	results map[T]*DistanceResult[T],
+	state *searchState[T],
+	search *treeSearch,
 	node *Node[T],
 ) []*Node[T] {
 	nodeSearchStart := time.Now()
@@ -83,40 +321,110 @@ func (wt *DistanceTrees[T]) evaluate(
 	}()
 
 	// If this node can never contain the search term, skip it and its ancestors
-	if !node.Contains(searchTerm) {
+	if !matchesNode(search, node) {
 		return nil
 	}
 
-	levenshteinDistance := levenshtein.LevenshteinDistance(searchTerm, node.GetKeyTerm())
+	wt.evaluateMatch(state, search, node, search.matchOffsets, -1)
 
-	for _, matchingNodeValue := range node.values {
-		var distances []*int
-		if existingResult, hasResult := results[matchingNodeValue]; hasResult {
-			distances = existingResult.Distances
-		} else {
-			distances = make([]*int, treeCount+1)
-			distanceResult := &DistanceResult[T]{
-				Result:    matchingNodeValue,
-				Distances: distances,
-			}
-			results[matchingNodeValue] = distanceResult
+	// Continue crawling up the tree
+	return wt.getParentCandidates(node)
+}
+
+// evaluateMatch measures the distance of the given node, whose key term is known to match the search term, from the
+// search term, recording it against each of the node's values that it matches more closely than any of their other
+// key terms. The given offsets of the matched runes from the end of the key term are used to build the match.
+// If the given distance is not negative, it is the already-known distance of the key term, which is not measured again.
+func (wt *DistanceTrees[T]) evaluateMatch(
+	state *searchState[T],
+	search *treeSearch,
+	node *Node[T],
+	offsetsFromEnd []int,
+	knownDistance float64,
+) {
+	keyTerm := node.GetKeyTerm()
+	// In a tokenized tree, the search term is only measured against as many of the key term's tokens as it has
+	measuredTerm := keyTerm
+	if search.query.tokenCount > 0 {
+		measuredTerm = leadingTokens(keyTerm, search.query.tokenCount)
+	}
+
+	keyTermDistance := knownDistance
+	if keyTermDistance < 0 {
+		if search.maxDistance >= 0 && search.isLengthBounded && exceedsDistance(search.measuredSearchTerm, measuredTerm, search.maxDistance) {
+			// This node is too far from the search term, but its ancestors, being shorter, may not be
+			return
 		}
+		keyTermDistance = search.measureDistance(measuredTerm)
+	}
 
-		if distances[treeIndex] != nil {
-			// the distance has already been calculated since this was a parent node to another node
-			// that's been visited; don't re-calculate it
-			continue
+	if search.maxDistance >= 0 && keyTermDistance > float64(search.maxDistance) {
+		return
+	}
+
+	// The match is shared by all the node's values at the same token position, so it is only built if one of them needs it
+	var treeMatch *TreeMatch
+	for valueIndex, matchingNodeValue := range node.values {
+		distanceResult, hasResult := state.results[matchingNodeValue]
+		if !hasResult {
+			distanceResult = wt.newDistanceResult(matchingNodeValue)
+		}
+
+		// Penalize and scale a copy of the distance, as it is shared with the node's other values
+		tokenPosition := node.getTokenPosition(valueIndex)
+		valueDistance := keyTermDistance + float64(tokenPosition)*search.tokenPositionPenalty
+		scaledDistance := valueDistance
+		if primaryDistanceFactor := matchingNodeValue.GetPrimaryDistanceFactor(); primaryDistanceFactor != nil {
+			scaledDistance *= *primaryDistanceFactor
 		}
 
-		primaryDistanceFactor := matchingNodeValue.GetPrimaryDistanceFactor()
-		if primaryDistanceFactor != nil {
-			levenshteinDistance = int(float64(levenshteinDistance) * *primaryDistanceFactor)
+		if hasResult && distanceResult.Distances[search.treeIndex] != nil && !isCloserMatch(
+			scaledDistance,
+			keyTerm,
+			distanceResult.treeDistances[search.treeIndex],
+			distanceResult.Matches[search.treeIndex].KeyTerm,
+		) {
+			// the value has already been matched under another of its key terms at least as closely; keep that match
+			continue
+		}
+		roundedDistance := int(scaledDistance) + wt.trees[search.treeIndex].distanceOffset
+		distanceResult.Distances[search.treeIndex] = &roundedDistance
+		distanceResult.treeDistances[search.treeIndex] = scaledDistance
+		distanceResult.valueIndices[search.treeIndex] = valueIndex
+
+		if treeMatch == nil || treeMatch.TokenPosition != tokenPosition {
+			keyTermSimilarity := getSimilarity(search.metric, search.measuredSearchTerm, measuredTerm, keyTermDistance)
+			treeMatch = newTreeMatch(search.treeIndex, wt.trees[search.treeIndex].Tree, keyTerm, valueDistance, keyTermSimilarity, tokenPosition, offsetsFromEnd)
 		}
-		weightedDistance := int(math.Pow(10, float64(treeIndex))) + levenshteinDistance
-		distances[treeIndex] = &weightedDistance
+		distanceResult.Matches[search.treeIndex] = treeMatch
+
+		wt.recordResult(state, distanceResult, hasResult)
 	}
+}
 
-	// Continue crawling up the tree
+// measureDistance measures the distance of the search term from the given measured term, stopping at the maximum
+// distance if the metric can.
+func (s *treeSearch) measureDistance(measuredTerm string) float64 {
+	if s.boundedMetric != nil {
+		// Key terms beyond the maximum distance are given a distance past it, and so are excluded
+		distance, _ := s.boundedMetric.BoundedDistance(s.measuredSearchTerm, measuredTerm, s.maxDistance)
+		return distance
+	}
+	return s.metric.Distance(s.measuredSearchTerm, measuredTerm)
+}
+
+// isCloserMatch determines if a match at the given distance under the given key term is closer than an existing match
+// at the given distance under the given key term, breaking ties between equally-distant key terms by their order so
+// that the match chosen does not depend on the order in which nodes are evaluated.
+func isCloserMatch(distance float64, keyTerm string, existingDistance float64, existingKeyTerm string) bool {
+	if distance != existingDistance {
+		return distance < existingDistance
+	}
+	return keyTerm < existingKeyTerm
+}
+
+// getParentCandidates gets the nodes to be examined after the given node when crawling up the tree.
+func (wt *DistanceTrees[T]) getParentCandidates(node *Node[T]) []*Node[T] {
 	if parentNode := node.parent; parentNode != nil {
 		return []*Node[T]{parentNode}
 	}
@@ -124,17 +432,57 @@ func (wt *DistanceTrees[T]) evaluate(
 	return nil
 }
 
-// searchTree searches the tree at the given index with the given search term, populating the results into the given results map.
-func (wt *DistanceTrees[T]) searchTree(ctx context.Context, treeIndex int, searchTerm string, results map[T]*DistanceResult[T]) error {
+// searchTree searches the tree at the given index with the given search state, populating the results into the state's results map.
+func (wt *DistanceTrees[T]) searchTree(ctx context.Context, treeIndex int, state *searchState[T]) error {
 	searchStart := time.Now()
 	defer func() {
 		_ = wt.timer.RecordTreeSearch(ctx, time.Since(searchStart))
 	}()
 
-	treeCount := len(wt.trees)
-	tree := wt.trees[treeIndex]
+	weightedTree := wt.trees[treeIndex]
+	// The key terms in the tree are normalized, so the search term must be as well for distances to be meaningful
+	query := weightedTree.Tree.PrepareQuery(state.searchTerm)
+	metric := weightedTree.Metric
+	if metric == nil {
+		metric = wt.metric
+	}
+	maxDistance, err := state.options.getMaxDistance(len(query.runes))
+	if err != nil {
+		return err
+	}
+	search := &treeSearch{
+		treeIndex:          treeIndex,
+		query:              query,
+		measuredSearchTerm: query.term,
+		matchOffsets:       make([]int, len(query.runes)),
+		maxDistance:        weightedTree.getMaxDistance(maxDistance),
+		metric:             metric,
+		isLengthBounded:    isLengthBounded(metric),
+		isLevenshtein:      isLevenshteinMetric(metric),
+		matchMode:          state.options.MatchMode,
+		prefixEditBudget:   state.options.PrefixEditBudget,
+	}
+	if weightedTree.Tree.tokenizer != nil {
+		search.tokenPositionPenalty = weightedTree.Tree.tokenPositionPenalty
+	}
+
+	if state.options.measuresSearchTermAsGiven {
+		search.measuredSearchTerm = state.searchTerm
+	}
+	if search.maxDistance >= 0 {
+		search.boundedMetric = getBoundedMetric(metric)
+	}
+	if search.matchMode == MatchModeSubsequence && countsTranspositions(metric) {
+		search.transpositionStates = newTranspositionStates(len(query.runes))
+	}
+
+	if search.matchMode.isTopDown() {
+		return wt.searchTreeTopDown(ctx, weightedTree.Tree, state, search)
+	}
 
-	currentNodes := tree.GetLeafNodes()
+	currentNodes := weightedTree.Tree.GetLeafNodes()
+	// evaluatedNodes tracks the nodes holding values that have already been evaluated in this tree
+	evaluatedNodes := make(map[*Node[T]]struct{})
 
 	for {
 		if ctxErr := context.Cause(ctx); ctxErr != nil {
@@ -143,18 +491,15 @@ func (wt *DistanceTrees[T]) searchTree(ctx context.Context, treeIndex int, searc
 
 		var nextNodes []*Node[T]
 		for _, currentNode := range currentNodes {
-			nodeValues := currentNode.values
-			if len(nodeValues) > 0 {
-				// If at least one of the node's values' distance has been calculated for this index,
-				// it can be assumed that all the node's distances have been calculated and
-				// this does not need to run again.
-				// Further, it can be assumed that this node's ancestors have been calculated elsewhere,
-				// so break out completely from this traversal.
-				if result0, hasResult0 := results[nodeValues[0]]; hasResult0 && result0.Distances[treeIndex] != nil {
+			if len(currentNode.values) > 0 {
+				// If this node has already been evaluated, it can be assumed that this node's ancestors have been
+				// evaluated elsewhere, so break out completely from this traversal.
+				if _, isEvaluated := evaluatedNodes[currentNode]; isEvaluated {
 					continue
 				}
+				evaluatedNodes[currentNode] = struct{}{}
 
-				nextEvaluationCandidates := wt.evaluate(ctx, searchTerm, treeIndex, treeCount, results, currentNode)
+				nextEvaluationCandidates := wt.evaluate(ctx, state, search, currentNode)
 				if len(nextEvaluationCandidates) > 0 {
 					nextNodes = append(nextNodes, nextEvaluationCandidates...)
 				}
@@ -173,35 +518,3 @@ func (wt *DistanceTrees[T]) searchTree(ctx context.Context, treeIndex int, searc
 
 	return nil
 }
-
-// sortResults sorts the given DistanceResult objects according to their comparative distances.
-func (wt *DistanceTrees[T]) sortResults(ctx context.Context, weightedResults []*DistanceResult[T]) {
-	sortStart := time.Now()
-	defer func() {
-		_ = wt.timer.RecordSortTime(ctx, time.Since(sortStart))
-	}()
-
-	sort.Slice(weightedResults, func(i, j int) bool {
-		for distanceIndex := 0; distanceIndex < len(weightedResults[i].Distances); distanceIndex++ {
-			distance := weightedResults[i].Distances[distanceIndex]
-			otherDistance := weightedResults[j].Distances[distanceIndex]
-			// If i's distance here is nil and j's is non-nil, then this should actually be ranked as _greater_
-			// because that means that i was not a Match for the search term. Conversely, if j is nil
-			// and i is non-nil, then it should be considered 'less than' for purposes of sorting, as that means
-			// i matched the search term.
-			if distance == nil {
-				if otherDistance != nil {
-					return false
-				}
-			} else if otherDistance == nil {
-				return true
-			} else if *distance < *otherDistance {
-				return true
-			} else if *distance > *otherDistance {
-				return false
-			}
-		}
-
-		return false
-	})
-}
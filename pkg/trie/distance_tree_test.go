@@ -2,43 +2,34 @@ package trie_test
 
 import (
 	"context"
-	_ "embed"
 	"fmt"
+	"github.com/coinbase/fuzzy-trie/pkg/levenshtein"
+	"github.com/coinbase/fuzzy-trie/pkg/similarity"
 	"github.com/coinbase/fuzzy-trie/pkg/trie"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"hash/fnv"
 	"strings"
-	"time"
 )
 
-//go:embed animals.txt
-var animalsText string
-
 var _ = Describe("DistanceTree", func() {
 	var ctx context.Context
 
+	newDistanceTreesWithWeights := func(trees []*trie.WeightedTree[*testComparableFuzzable]) *trie.DistanceTrees[*testComparableFuzzable] {
+		distanceTrees, err := trie.NewDistanceTreesWithWeights(trees)
+		Expect(err).ToNot(HaveOccurred(), "building the trees should not fail")
+		return distanceTrees
+	}
+
 	BeforeEach(func() {
-		var cancelFn context.CancelFunc
-		ctx, cancelFn = context.WithTimeout(context.Background(), 5*time.Second)
-		DeferCleanup(cancelFn)
+		ctx = newTestContext()
 	})
 
 	Context("Search", func() {
 		var tree *trie.DistanceTrees[*testComparableFuzzable]
 
 		BeforeEach(func() {
-			animals := strings.Split(animalsText, "\n")
-			animalsFuzzable := make([]*testComparableFuzzable, len(animals))
-			for i, animal := range animals {
-				animalsFuzzable[i] = newTestComparableFuzzable(animal)
-			}
-
-			animalsTree, err := trie.LoadTree[*testComparableFuzzable](ctx, animalsFuzzable, func(_ context.Context, item *testComparableFuzzable) (string, error) {
-				return item.text, nil
-			})
-			Expect(err).ToNot(HaveOccurred(), "loading the animals tree should not fail")
-
+			animalsTree := loadTestTree(ctx, newTestAnimals())
 			tree = trie.NewDistanceTrees[*testComparableFuzzable]([]*trie.Tree[*testComparableFuzzable]{animalsTree})
 		})
 
@@ -66,21 +57,867 @@ var _ = Describe("DistanceTree", func() {
 				Expect(results[6].Result.text).To(Equal("New World quail"), "the 6th element should be correct")
 			})
 		})
+
+		Context("match highlighting", func() {
+			It("should return the positions of the matched characters", func() {
+				results, err := tree.Search(ctx, "wol")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results[6].Result.text).To(Equal("New World quail"), "the 6th element should be correct")
+				Expect(results[6].Matches).To(HaveLen(1), "there should be a match for each tree")
+				Expect(results[6].Matches[0].KeyTerm).To(Equal("NEW WORLD QUAIL"), "the normalized key term should be returned")
+				Expect(results[6].Matches[0].Positions).To(Equal([]int{4, 5, 14}), "the positions of the matched characters should be returned")
+			})
+
+			It("should return the positions of contiguous matches", func() {
+				results, err := tree.Search(ctx, "cat")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results[7].Result.text).To(Equal("Wildcat"), "the 7th element should be correct")
+				Expect(results[7].Matches[0].Positions).To(Equal([]int{4, 5, 6}), "the positions of the matched characters should be returned")
+			})
+		})
+
+		Context("explanation", func() {
+			It("should not explain results by default", func() {
+				results, err := tree.Search(ctx, "cat")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				for _, result := range results {
+					Expect(result.Explanation).To(BeNil(), "no explanation should be given")
+				}
+			})
+
+			It("should explain the scoring and ranking of each result", func() {
+				results, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{Explain: true})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(HaveLen(21), "the correct number of results should be returned")
+
+				Expect(results[0].Explanation.RankedBy).To(Equal(trie.RankingStepNone), "the first result should not be ranked against another")
+				Expect(results[1].Explanation.RankedBy).To(Equal(trie.RankingStepNone), "identical results should be ranked equally")
+
+				wildcat := results[7]
+				Expect(wildcat.Result.text).To(Equal("Wildcat"), "the 7th element should be correct")
+				Expect(wildcat.Explanation.RankedBy).To(Equal(trie.RankingStepDistance), "the result should be ranked by distance")
+				Expect(wildcat.Explanation.RankingDistanceIndex).To(Equal(0), "the tree's distance should decide the ranking")
+				Expect(wildcat.Explanation.Trees).To(HaveLen(1), "there should be a breakdown for each tree")
+				Expect(wildcat.Explanation.Trees[0].KeyTerm).To(Equal("WILDCAT"), "the key term should be explained")
+				Expect(wildcat.Explanation.Trees[0].RawDistance).To(BeNumerically("~", 4), "the raw distance should be explained")
+				Expect(wildcat.Explanation.Trees[0].PrimaryDistanceFactor).To(BeNil(), "there should be no primary distance factor")
+				Expect(wildcat.Explanation.Trees[0].ScaledDistance).To(BeNumerically("~", 4), "the scaled distance should be explained")
+				Expect(wildcat.Explanation.Trees[0].Weight).To(BeNumerically("~", 1), "the weight should be explained")
+				Expect(wildcat.Explanation.Trees[0].Score).To(BeNumerically("~", 1.0/5), "the score should be explained")
+				Expect(wildcat.Explanation.Score).To(BeNumerically("~", wildcat.Score), "the combined score should be explained")
+				Expect(wildcat.Explanation.SecondaryDistances).To(HaveLen(2), "the secondary distances should be explained")
+				Expect(*wildcat.Explanation.SecondaryDistances[0]).To(Equal(7), "the secondary distances should be explained")
+				Expect(wildcat.Explanation.SortingGroup).To(Equal(1), "the sorting group should be explained")
+
+				catfish := results[8]
+				Expect(catfish.Result.text).To(Equal("Catfish"), "the 8th element should be correct")
+				Expect(catfish.Explanation.RankedBy).To(Equal(trie.RankingStepDistance), "the result should be ranked by distance")
+				Expect(catfish.Explanation.RankingDistanceIndex).To(Equal(len(catfish.Distances)-1), "the last secondary distance should decide the ranking")
+			})
+
+			It("should explain the ranking of the first result of a page against the previous page", func() {
+				results, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{Explain: true, Offset: 7, Limit: 1})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(HaveLen(1), "the page should be returned")
+				Expect(results[0].Result.text).To(Equal("Wildcat"), "the 7th element should be correct")
+				Expect(results[0].Explanation.RankedBy).To(Equal(trie.RankingStepDistance), "the result should be ranked against the previous page")
+			})
+		})
+
+		Context("search term normalization", func() {
+			It("should measure distances from the normalized search term", func() {
+				for _, searchTerm := range []string{"cat", "CAT", "cAt"} {
+					results, err := tree.SearchWithOptions(ctx, searchTerm, trie.SearchOptions{})
+					Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+					Expect(results[0].Result.text).To(Equal("Cat"), "the 0th element should be correct for '%s'", searchTerm)
+					Expect(*results[0].Distances[0]).To(Equal(1), "'%s' should exactly match 'Cat', offset by 1 in the first tree", searchTerm)
+					Expect(results[7].Result.text).To(Equal("Wildcat"), "the 7th element should be correct for '%s'", searchTerm)
+					Expect(*results[7].Distances[0]).To(Equal(5), "'%s' should be 4 edits from 'Wildcat', offset by 1 in the first tree", searchTerm)
+				}
+			})
+
+			It("should measure distances from the search term as given when searching without options", func() {
+				results, err := tree.Search(ctx, "cat")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results[0].Result.text).To(Equal("Cat"), "the 0th element should be correct")
+				Expect(*results[0].Distances[0]).To(Equal(4), "'cat' should be 3 edits from the normalized 'CAT', offset by 1 in the first tree")
+			})
+		})
+
+		Context("maximum distance", func() {
+			It("should only return exact matches when no distance is allowed", func() {
+				maxDistance := 0
+				results, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{MaxDistance: &maxDistance})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(HaveLen(3), "only the three 'Cat' entries should be returned")
+				for _, result := range results {
+					Expect(result.Result.text).To(Equal("Cat"), "only exact matches should be returned")
+				}
+			})
+
+			It("should exclude results beyond the maximum distance", func() {
+				maxDistance := 3
+				results, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{MaxDistance: &maxDistance})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).ToNot(BeEmpty(), "results should be returned")
+				var texts []string
+				for _, result := range results {
+					texts = append(texts, result.Result.text)
+					Expect(len(result.Result.text)-len("cat")).To(BeNumerically("<=", maxDistance), "'%s' should be within the maximum distance", result.Result.text)
+				}
+				Expect(texts).To(ContainElement("Cattle"), "results within the maximum distance should be returned")
+				Expect(texts).ToNot(ContainElement("Wildcat"), "results beyond the maximum distance should not be returned")
+			})
+
+			It("should reject a negative maximum distance", func() {
+				maxDistance := -1
+				_, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{MaxDistance: &maxDistance})
+				Expect(err).To(MatchError(trie.ErrInvalidSearchOptions), "a negative maximum distance should be rejected")
+			})
+		})
+
+		Context("match modes", func() {
+			findText := func(results []*trie.DistanceResult[*testComparableFuzzable], text string) *trie.DistanceResult[*testComparableFuzzable] {
+				for _, result := range results {
+					if result.Result.text == text {
+						return result
+					}
+				}
+				return nil
+			}
+
+			searchTexts := func(searchTerm string, options trie.SearchOptions) []string {
+				results, err := tree.SearchWithOptions(ctx, searchTerm, options)
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				texts := make([]string, len(results))
+				for i, result := range results {
+					texts[i] = result.Result.text
+				}
+				return texts
+			}
+
+			It("should match non-contiguous characters by default", func() {
+				Expect(searchTexts("wol", trie.SearchOptions{})).To(ContainElement("New World quail"), "subsequences should match")
+			})
+
+			It("should only match contiguous substrings", func() {
+				texts := searchTexts("cat", trie.SearchOptions{MatchMode: trie.MatchModeSubstring})
+				Expect(texts).To(ContainElements("Cat", "Wildcat", "Catfish"), "key terms containing the search term should match")
+				Expect(texts).ToNot(ContainElement("Domestic Bactrian camel"), "subsequences should not match")
+
+				results, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{MatchMode: trie.MatchModeSubstring})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				for _, result := range results {
+					if result.Result.text == "Wildcat" {
+						Expect(result.Matches[0].Positions).To(Equal([]int{4, 5, 6}), "the positions of the substring should be returned")
+					}
+				}
+			})
+
+			It("should only match prefixes", func() {
+				texts := searchTexts("cat", trie.SearchOptions{MatchMode: trie.MatchModePrefix})
+				Expect(texts).To(ConsistOf("Cat", "Cat", "Cat", "Catshark", "Caterpillar", "Catfish", "Cattle", "Cattle", "Cattle"), "only key terms beginning with the search term should match")
+				Expect(texts[0]).To(Equal("Cat"), "the exact match should be ranked first")
+			})
+
+			It("should only match exact key terms", func() {
+				Expect(searchTexts("cat", trie.SearchOptions{MatchMode: trie.MatchModeExact})).To(ConsistOf("Cat", "Cat", "Cat"), "only identical key terms should match")
+				Expect(searchTexts("ca", trie.SearchOptions{MatchMode: trie.MatchModeExact})).To(BeEmpty(), "prefixes should not match")
+			})
+
+			It("should match prefixes within the edit budget", func() {
+				Expect(searchTexts("wlf", trie.SearchOptions{MatchMode: trie.MatchModeFuzzyPrefix})).To(BeEmpty(), "no edits should be allowed by default")
+
+				texts := searchTexts("wlf", trie.SearchOptions{MatchMode: trie.MatchModeFuzzyPrefix, PrefixEditBudget: 1})
+				Expect(texts).To(ContainElement("Wolf"), "prefixes within the budget should match")
+				Expect(texts).ToNot(ContainElement("Wolverine"), "prefixes beyond the budget should not match")
+
+				results, err := tree.SearchWithOptions(ctx, "wolv", trie.SearchOptions{MatchMode: trie.MatchModeFuzzyPrefix, PrefixEditBudget: 1})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				for _, result := range results {
+					if result.Result.text == "Wolverine" {
+						Expect(result.Matches[0].Positions).To(Equal([]int{0, 1, 2, 3}), "the positions of the matched prefix should be returned")
+					}
+				}
+			})
+
+			It("should reject an undefined match mode or a negative edit budget", func() {
+				_, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{MatchMode: trie.MatchMode(42)})
+				Expect(err).To(MatchError(trie.ErrInvalidSearchOptions), "an undefined match mode should be rejected")
+
+				_, err = tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{MatchMode: trie.MatchModeFuzzyPrefix, PrefixEditBudget: -1})
+				Expect(err).To(MatchError(trie.ErrInvalidSearchOptions), "a negative edit budget should be rejected")
+			})
+
+			It("should match key terms within the maximum distance by walking down the tree", func() {
+				maxDistance := 1
+				results, err := tree.SearchWithOptions(ctx, "cst", trie.SearchOptions{MatchMode: trie.MatchModeFuzzy, MaxDistance: &maxDistance})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).ToNot(BeEmpty(), "results should be returned")
+				for _, result := range results {
+					Expect(result.Matches[0].Distance).To(BeNumerically("<=", maxDistance), "'%s' should be within the maximum distance", result.Result.text)
+				}
+				Expect(findText(results, "Cat")).ToNot(BeNil(), "key terms not containing the search term should match")
+			})
+
+			It("should find every result found by walking up the tree at the same distance", func() {
+				resultDistances := func(results []*trie.DistanceResult[*testComparableFuzzable]) []string {
+					distances := make([]string, len(results))
+					for i, result := range results {
+						distances[i] = fmt.Sprintf("%s=%d", result.Result.text, *result.Distances[0])
+					}
+					return distances
+				}
+
+				maxDistance := 2
+				for _, searchTerm := range []string{"cat", "wolf", "e", "domestic"} {
+					subsequenceResults, err := tree.SearchWithOptions(ctx, searchTerm, trie.SearchOptions{MaxDistance: &maxDistance})
+					Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+					fuzzyResults, err := tree.SearchWithOptions(ctx, searchTerm, trie.SearchOptions{MatchMode: trie.MatchModeFuzzy, MaxDistance: &maxDistance})
+					Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+					Expect(resultDistances(fuzzyResults)).To(ContainElements(resultDistances(subsequenceResults)), "the results for '%s' should include those containing the search term", searchTerm)
+				}
+			})
+		})
+
+		Context("multi-term queries", func() {
+			findResult := func(results []*trie.DistanceResult[*testComparableFuzzable], text string) *trie.DistanceResult[*testComparableFuzzable] {
+				for _, result := range results {
+					if result.Result.text == text {
+						return result
+					}
+				}
+				return nil
+			}
+
+			It("should only return results matching every query term with AND semantics", func() {
+				results, err := tree.SearchWithOptions(ctx, "domestic camel", trie.SearchOptions{QueryOperator: trie.QueryOperatorAnd})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).ToNot(BeEmpty(), "results should be returned")
+				for _, result := range results {
+					Expect(result.TermMatches).To(HaveLen(2), "there should be matches for each query term")
+					Expect(result.TermMatches).ToNot(ContainElement(BeNil()), "'%s' should match every query term", result.Result.text)
+				}
+				Expect(findResult(results, "Domestic Bactrian camel")).ToNot(BeNil(), "results matching both query terms should be returned")
+				Expect(findResult(results, "Camel")).To(BeNil(), "results matching one query term should not be returned")
+			})
+
+			It("should return results matching any query term with OR semantics", func() {
+				results, err := tree.SearchWithOptions(ctx, "domestic camel", trie.SearchOptions{
+					QueryOperator: trie.QueryOperatorOr,
+					Explain:       true,
+				})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(findResult(results, "Domestic Bactrian camel")).ToNot(BeNil(), "results matching both query terms should be returned")
+
+				camel := findResult(results, "Camel")
+				Expect(camel).ToNot(BeNil(), "results matching one query term should be returned")
+				Expect(camel.TermMatches[0]).To(BeNil(), "the unmatched query term should have no matches")
+				Expect(camel.TermMatches[1][0].KeyTerm).To(Equal("CAMEL"), "the matched query term should have a match")
+				Expect(*camel.Distances[0]).To(Equal(1+len("domestic")), "the unmatched query term should count as its length")
+				Expect(camel.Explanation.Trees[0].RawDistance).To(Equal(float64(len("domestic"))), "the combined distance should be explained")
+			})
+
+			It("should search for a single query term without the characters around it", func() {
+				results, err := tree.SearchWithOptions(ctx, "cat!", trie.SearchOptions{QueryOperator: trie.QueryOperatorAnd})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).ToNot(BeEmpty(), "results should be returned")
+				Expect(results[0].Result.text).To(Equal("Cat"), "the exact match should be first")
+				Expect(results[0].Matches[0].Distance).To(BeZero(), "the punctuation should not be measured")
+			})
+
+			It("should aggregate the distances of each query term", func() {
+				results, err := tree.SearchWithOptions(ctx, "wild cat", trie.SearchOptions{QueryOperator: trie.QueryOperatorAnd})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).ToNot(BeEmpty(), "results should be returned")
+				Expect(results[0].Result.text).To(Equal("Wildcat"), "the result closest to both query terms should be first")
+				// "WILD" is 3 edits from "WILDCAT" and "CAT" is 4
+				Expect(*results[0].Distances[0]).To(Equal(1+7), "the distances of both query terms should be summed")
+			})
+
+			It("should average the similarities of each query term", func() {
+				results, err := tree.SearchWithOptions(ctx, "wild cat", trie.SearchOptions{QueryOperator: trie.QueryOperatorOr})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				wildcat := findResult(results, "Wildcat")
+				Expect(wildcat).ToNot(BeNil(), "results matching both query terms should be returned")
+				// "WILD" is 3 edits from "WILDCAT" and "CAT" is 4
+				Expect(wildcat.Similarity).To(BeNumerically("~", (1-3.0/7+1-4.0/7)/2), "the similarities of both query terms should be averaged")
+
+				cat := findResult(results, "Cat")
+				Expect(cat).ToNot(BeNil(), "results matching one query term should be returned")
+				Expect(cat.Similarity).To(BeNumerically("~", 0.5), "the unmatched query term should count as dissimilar")
+			})
+		})
+
+		Context("similarity", func() {
+			It("should normalize the distance of each match by the lengths of the terms", func() {
+				results, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results[0].Result.text).To(Equal("Cat"), "the 0th element should be correct")
+				Expect(results[0].Similarity).To(BeNumerically("~", 1), "an exact match should be identical")
+				Expect(results[7].Result.text).To(Equal("Wildcat"), "the 7th element should be correct")
+				Expect(results[7].Matches[0].Similarity).To(BeNumerically("~", 1-4.0/7), "the similarity of the match should be normalized")
+				Expect(results[7].Similarity).To(Equal(results[7].Matches[0].Similarity), "the similarity of the result should be that of its match")
+			})
+
+			It("should find typos in long terms more similar than typos in short terms", func() {
+				maxDistance := 1
+				shortResults, err := tree.SearchWithOptions(ctx, "cst", trie.SearchOptions{MatchMode: trie.MatchModeFuzzy, MaxDistance: &maxDistance})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(shortResults).ToNot(BeEmpty(), "results should be returned")
+				Expect(shortResults[0].Result.text).To(Equal("Cat"), "the short term should be found")
+
+				longResults, err := tree.SearchWithOptions(ctx, "domestic bactrian camek", trie.SearchOptions{MatchMode: trie.MatchModeFuzzy, MaxDistance: &maxDistance})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(longResults).ToNot(BeEmpty(), "results should be returned")
+				Expect(longResults[0].Result.text).To(Equal("Domestic Bactrian camel"), "the long term should be found")
+
+				Expect(*shortResults[0].Distances[0]).To(Equal(*longResults[0].Distances[0]), "both typos should be a single edit")
+				Expect(shortResults[0].Similarity).To(BeNumerically("~", 1-1.0/3), "the short term's similarity should be normalized")
+				Expect(longResults[0].Similarity).To(BeNumerically("~", 1-1.0/23), "the long term's similarity should be normalized")
+			})
+		})
+
+		Context("typo budget", func() {
+			It("should allow no typos for short search terms", func() {
+				results, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{TypoBudget: trie.DefaultTypoBudget})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(HaveLen(3), "only the three 'Cat' entries should be returned")
+			})
+
+			It("should allow typos in proportion to the length of the search term", func() {
+				results, err := tree.SearchWithOptions(ctx, "wolf", trie.SearchOptions{TypoBudget: trie.DefaultTypoBudget})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).ToNot(BeEmpty(), "results should be returned")
+				Expect(results[0].Result.text).To(Equal("Wolf"), "the exact match should be first")
+				for _, result := range results {
+					Expect(len(result.Result.text)).To(BeNumerically("<=", 5), "'%s' should be within one typo", result.Result.text)
+				}
+			})
+
+			It("should apply the smaller of the maximum distance and the typo budget", func() {
+				maxDistance := 0
+				results, err := tree.SearchWithOptions(ctx, "wolverin", trie.SearchOptions{
+					MaxDistance: &maxDistance,
+					TypoBudget:  trie.DefaultTypoBudget,
+				})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(BeEmpty(), "no result should be within the maximum distance")
+			})
+
+			It("should reject a negative typo budget", func() {
+				_, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{TypoBudget: func(searchTermLength int) int {
+					return searchTermLength - 4
+				}})
+				Expect(err).To(MatchError(trie.ErrInvalidSearchOptions), "a negative typo budget should be rejected")
+			})
+		})
+
+		Context("pagination", func() {
+			resultTexts := func(results []*trie.DistanceResult[*testComparableFuzzable]) []string {
+				texts := make([]string, len(results))
+				for i, result := range results {
+					texts[i] = result.Result.text
+				}
+				return texts
+			}
+
+			It("should return only the best results up to the limit", func() {
+				allResults, err := tree.Search(ctx, "e")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+
+				results, err := tree.SearchWithOptions(ctx, "e", trie.SearchOptions{Limit: 10})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(resultTexts(results)).To(Equal(resultTexts(allResults[:10])), "the limited results should be the best of the full results")
+			})
+
+			It("should page through the full result set", func() {
+				allResults, err := tree.Search(ctx, "e")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+
+				var pagedResults []*trie.DistanceResult[*testComparableFuzzable]
+				for offset := 0; ; offset += 25 {
+					page, pageErr := tree.SearchWithOptions(ctx, "e", trie.SearchOptions{Limit: 25, Offset: offset})
+					Expect(pageErr).ToNot(HaveOccurred(), "searching the tree should not fail")
+					Expect(len(page)).To(BeNumerically("<=", 25), "a page should not exceed the limit")
+					if len(page) == 0 {
+						break
+					}
+					pagedResults = append(pagedResults, page...)
+				}
+
+				Expect(resultTexts(pagedResults)).To(Equal(resultTexts(allResults)), "the pages should make up the full result set in order")
+			})
+
+			It("should page through equally-ranked results without repeating or skipping any", func() {
+				allResults, err := tree.Search(ctx, "cat")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+
+				for offset, expectedResult := range allResults {
+					page, pageErr := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{Limit: 1, Offset: offset})
+					Expect(pageErr).ToNot(HaveOccurred(), "searching the tree should not fail")
+					Expect(page).To(HaveLen(1), "the page should be filled")
+					Expect(page[0].Result).To(BeIdenticalTo(expectedResult.Result), "the page at offset %d should hold the same item as the full results", offset)
+				}
+			})
+
+			It("should keep only the best results while searching a tokenized tree", func() {
+				// Items are matched under each of their words in turn, so their ranks improve as they are matched again
+				animalsTree := loadTestTree(ctx, newTestAnimals(), trie.WithTokenizer(trie.WordTokenizer, 0.5))
+				tokenizedTree := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{animalsTree})
+
+				allResults, err := tokenizedTree.SearchWithOptions(ctx, "a", trie.SearchOptions{})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				for _, limit := range []int{1, 7, 50} {
+					results, searchErr := tokenizedTree.SearchWithOptions(ctx, "a", trie.SearchOptions{Limit: limit, Offset: 3})
+					Expect(searchErr).ToNot(HaveOccurred(), "searching the tree should not fail")
+					Expect(results).To(HaveLen(limit), "the page should be filled")
+					for resultIndex, result := range results {
+						Expect(result.Result).To(BeIdenticalTo(allResults[3+resultIndex].Result), "the result at offset %d should be the same as in the full results", 3+resultIndex)
+						Expect(result.Matches[0].KeyTerm).To(Equal(allResults[3+resultIndex].Matches[0].KeyTerm), "the closest match should be kept")
+					}
+				}
+			})
+
+			It("should reject a negative limit or offset", func() {
+				_, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{Offset: -1})
+				Expect(err).To(MatchError(trie.ErrInvalidSearchOptions), "a negative offset should be rejected")
+
+				_, err = tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{Limit: -1})
+				Expect(err).To(MatchError(trie.ErrInvalidSearchOptions), "a negative limit should be rejected")
+			})
+
+			It("should skip results without a limit", func() {
+				allResults, err := tree.Search(ctx, "wol")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+
+				results, err := tree.SearchWithOptions(ctx, "wol", trie.SearchOptions{Offset: 3})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(resultTexts(results)).To(Equal(resultTexts(allResults[3:])), "the results should skip the offset")
+
+				results, err = tree.SearchWithOptions(ctx, "wol", trie.SearchOptions{Offset: len(allResults)})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(BeEmpty(), "an offset past the results should return no results")
+			})
+		})
+
+		Context("weighted trees", func() {
+			var wolfhound, arcticWolf *testComparableFuzzable
+			var nameTree, lastWordTree *trie.Tree[*testComparableFuzzable]
+
+			BeforeEach(func() {
+				wolfhound = newTestComparableFuzzable("Wolfhound")
+				arcticWolf = newTestComparableFuzzable("Arctic wolf")
+				items := []*testComparableFuzzable{wolfhound, arcticWolf}
+
+				var err error
+				nameTree = loadTestTree(ctx, items, trie.WithTreeName("name"))
+
+				lastWordTree, err = trie.LoadTree(ctx, items, func(_ context.Context, item *testComparableFuzzable) (string, error) {
+					words := strings.Fields(item.text)
+					return words[len(words)-1], nil
+				}, trie.WithTreeName("last word"))
+				Expect(err).ToNot(HaveOccurred(), "loading the last word tree should not fail")
+			})
+
+			It("should rank by distance in each tree in turn without weights", func() {
+				results, err := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{nameTree, lastWordTree}).SearchWithOptions(ctx, "wolf", trie.SearchOptions{})
+				Expect(err).ToNot(HaveOccurred(), "searching the trees should not fail")
+				Expect(results).To(HaveLen(2), "both items should be returned")
+				Expect(results[0].Result).To(Equal(wolfhound), "the item closest in the first tree should be first")
+				Expect(*results[0].Distances[0]).To(Equal(1+5), "the distance in the first tree should be offset by 1")
+				Expect(*results[0].Distances[1]).To(Equal(10+5), "the distance in the second tree should be offset by 10")
+				Expect(*results[1].Distances[1]).To(Equal(10+0), "the distance in the second tree should be offset by 10")
+				Expect(results[1].Matches[1].Distance).To(BeNumerically("~", 0), "the match's distance should not be offset")
+			})
+
+			It("should rank by combined score with weights", func() {
+				results, err := newDistanceTreesWithWeights([]*trie.WeightedTree[*testComparableFuzzable]{
+					{Tree: nameTree, Weight: 1},
+					{Tree: lastWordTree, Weight: 1},
+				}).SearchWithOptions(ctx, "wolf", trie.SearchOptions{})
+				Expect(err).ToNot(HaveOccurred(), "searching the trees should not fail")
+				Expect(results).To(HaveLen(2), "both items should be returned")
+				Expect(results[0].Result).To(Equal(arcticWolf), "the item with the best combined score should be first")
+				Expect(results[0].Score).To(BeNumerically("~", 1.0/8+1.0), "the score should combine both trees")
+				Expect(results[1].Score).To(BeNumerically("~", 1.0/6+1.0/6), "the score should combine both trees")
+			})
+
+			It("should explain the ranking by combined score", func() {
+				results, err := newDistanceTreesWithWeights([]*trie.WeightedTree[*testComparableFuzzable]{
+					{Tree: nameTree, Weight: 1},
+					{Tree: lastWordTree, Weight: 2},
+				}).SearchWithOptions(ctx, "wolf", trie.SearchOptions{Explain: true})
+				Expect(err).ToNot(HaveOccurred(), "searching the trees should not fail")
+				Expect(results).To(HaveLen(2), "both items should be returned")
+				Expect(results[1].Result).To(Equal(wolfhound), "the item with the worst combined score should be last")
+				Expect(results[1].Explanation.RankedBy).To(Equal(trie.RankingStepScore), "the result should be ranked by score")
+				Expect(results[1].Explanation.RankingDistanceIndex).To(Equal(-1), "no distance should decide the ranking")
+				Expect(results[1].Explanation.Trees[1].TreeName).To(Equal("last word"), "the tree should be identified")
+				Expect(results[1].Explanation.Trees[1].Weight).To(BeNumerically("~", 2), "the weight should be explained")
+				Expect(results[1].Explanation.Trees[1].Score).To(BeNumerically("~", 2.0/6), "the weighted score should be explained")
+			})
+
+			It("should apply a custom scorer", func() {
+				results, err := newDistanceTreesWithWeights([]*trie.WeightedTree[*testComparableFuzzable]{
+					{Tree: nameTree, Weight: 2, Scorer: func(distance float64) float64 {
+						return 10 - distance
+					}},
+				}).SearchWithOptions(ctx, "wolf", trie.SearchOptions{})
+				Expect(err).ToNot(HaveOccurred(), "searching the trees should not fail")
+				Expect(results).To(HaveLen(2), "both items should be returned")
+				Expect(results[0].Score).To(BeNumerically("~", 10), "the custom scorer should be weighted")
+				Expect(results[1].Score).To(BeNumerically("~", 6), "the custom scorer should be weighted")
+			})
+
+			It("should return the match in each tree", func() {
+				maxDistance := 0
+				results, err := newDistanceTreesWithWeights([]*trie.WeightedTree[*testComparableFuzzable]{
+					{Tree: nameTree, Weight: 1},
+					{Tree: lastWordTree, Weight: 1, MaxDistance: &maxDistance},
+				}).SearchWithOptions(ctx, "wolf", trie.SearchOptions{})
+				Expect(err).ToNot(HaveOccurred(), "searching the trees should not fail")
+				Expect(results).To(HaveLen(2), "both items should be returned")
+				Expect(results[0].Result).To(Equal(arcticWolf), "the item with the best combined score should be first")
+				Expect(results[0].Matches[0].KeyTerm).To(Equal("ARCTIC WOLF"), "the key term in the first tree should be returned")
+				Expect(results[0].Matches[0].Positions).To(Equal([]int{7, 8, 9, 10}), "the positions in the first tree should be returned")
+				Expect(results[0].Matches[1].KeyTerm).To(Equal("WOLF"), "the key term in the second tree should be returned")
+				Expect(results[0].Matches[1].Positions).To(Equal([]int{0, 1, 2, 3}), "the positions in the second tree should be returned")
+				Expect(results[1].Matches[0].KeyTerm).To(Equal("WOLFHOUND"), "the key term in the first tree should be returned")
+				Expect(results[1].Matches[1]).To(BeNil(), "there should be no match beyond the second tree's maximum distance")
+			})
+
+			It("should identify the tree and distance of each match", func() {
+				primaryDistanceFactor := 2.0
+				arcticWolf.primaryDistanceFactor = &primaryDistanceFactor
+
+				results, err := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{nameTree, lastWordTree}).SearchWithOptions(ctx, "wolf", trie.SearchOptions{})
+				Expect(err).ToNot(HaveOccurred(), "searching the trees should not fail")
+				Expect(results).To(HaveLen(2), "both items should be returned")
+				Expect(results[1].Result).To(Equal(arcticWolf), "the scaled item should be ranked last")
+				for treeIndex, treeName := range []string{"name", "last word"} {
+					Expect(results[1].Matches[treeIndex].TreeIndex).To(Equal(treeIndex), "the index of the tree should be returned")
+					Expect(results[1].Matches[treeIndex].TreeName).To(Equal(treeName), "the name of the tree should be returned")
+				}
+				Expect(results[1].Matches[0].Distance).To(BeNumerically("~", 7), "the distance should not be scaled")
+				Expect(*results[1].Distances[0]).To(Equal(1+14), "the ranked distance should be scaled and offset")
+				Expect(results[1].Matches[1].Distance).To(BeNumerically("~", 0), "the distance should not be scaled")
+			})
+
+			It("should fail to build weighted trees with a missing tree", func() {
+				_, err := trie.NewDistanceTreesWithWeights([]*trie.WeightedTree[*testComparableFuzzable]{{Tree: nameTree, Weight: 1}, nil})
+				Expect(err).To(HaveOccurred(), "a nil weighted tree should be rejected")
+
+				_, err = trie.NewDistanceTreesWithWeights([]*trie.WeightedTree[*testComparableFuzzable]{{Weight: 1}})
+				Expect(err).To(HaveOccurred(), "a weighted tree without a tree should be rejected")
+			})
+
+			It("should apply a per-tree maximum distance", func() {
+				maxDistance := 0
+				results, err := newDistanceTreesWithWeights([]*trie.WeightedTree[*testComparableFuzzable]{
+					{Tree: nameTree, Weight: 1, MaxDistance: &maxDistance},
+					{Tree: lastWordTree, Weight: 1},
+				}).Search(ctx, "wolf")
+				Expect(err).ToNot(HaveOccurred(), "searching the trees should not fail")
+				Expect(results).To(HaveLen(2), "both items should be returned from the second tree")
+				for _, result := range results {
+					Expect(result.Distances[0]).To(BeNil(), "no item should match within the first tree's maximum distance")
+				}
+			})
+		})
+
+		Context("metrics", func() {
+			It("should measure distances with the given function", func() {
+				var measuredSearchTerms []string
+				tree.SetDistanceFunc(func(searchTerm string, keyTerm string) int {
+					measuredSearchTerms = append(measuredSearchTerms, searchTerm)
+					return len(keyTerm)
+				})
+
+				results, err := tree.SearchWithOptions(ctx, "wol", trie.SearchOptions{})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(HaveLen(8), "the correct number of results should be returned")
+				Expect(measuredSearchTerms).To(HaveEach("WOL"), "the function should be given the normalized search term")
+				for _, result := range results {
+					Expect(*result.Distances[0]).To(Equal(1+len(result.Result.text)), "the distance should be measured by the function")
+				}
+			})
+
+			It("should support transposition-aware distances", func() {
+				tree.SetMetric(trie.DamerauLevenshteinMetric)
+
+				results, err := tree.SearchWithOptions(ctx, "act", trie.SearchOptions{})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).ToNot(BeEmpty(), "results should be returned")
+				Expect(results[0].Result.text).To(Equal("Cat"), "the transposed item should be the best match")
+				Expect(results[0].Matches[0].Distance).To(BeNumerically("~", 1), "the transposition should be a single edit")
+			})
+
+			It("should match transposed characters with transposition-aware metrics", func() {
+				btc := newTestComparableFuzzable("BTC")
+				symbolTree := loadTestTree(ctx, []*testComparableFuzzable{btc, newTestComparableFuzzable("ETH")})
+				symbolTrees := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{symbolTree})
+
+				results, err := symbolTrees.Search(ctx, "tbc")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(BeEmpty(), "transposed characters should not match with the Levenshtein distance")
+
+				symbolTrees.SetMetric(trie.DamerauLevenshteinMetric)
+				maxDistance := 1
+				for _, matchMode := range []trie.MatchMode{trie.MatchModeSubsequence, trie.MatchModeFuzzy} {
+					results, err = symbolTrees.SearchWithOptions(ctx, "tbc", trie.SearchOptions{MatchMode: matchMode, MaxDistance: &maxDistance})
+					Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+					Expect(results).To(HaveLen(1), "the transposed item should be found in mode %d", matchMode)
+					Expect(results[0].Result).To(Equal(btc), "the transposed item should be found in mode %d", matchMode)
+					Expect(results[0].Matches[0].Distance).To(BeNumerically("~", 1), "the transposition should be a single edit in mode %d", matchMode)
+				}
+				Expect(results[0].Matches[0].Positions).To(Equal([]int{0, 1, 2}), "the positions of the closest prefix should be returned")
+
+				results, err = symbolTrees.Search(ctx, "tbc")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(HaveLen(1), "the transposed item should be found")
+				Expect(results[0].Matches[0].Positions).To(Equal([]int{1, 0, 2}), "the positions of the transposed characters should be returned")
+			})
+
+			It("should rank by Jaro-Winkler distances", func() {
+				animalsTree := loadTestTree(ctx, newTestAnimals())
+
+				// The fractional distances are only reflected in full by the scores of weighted trees
+				results, err := newDistanceTreesWithWeights([]*trie.WeightedTree[*testComparableFuzzable]{
+					{Tree: animalsTree, Weight: 1, Metric: trie.JaroWinklerMetric},
+				}).SearchWithOptions(ctx, "wolf", trie.SearchOptions{})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).ToNot(BeEmpty(), "results should be returned")
+				Expect(results[0].Result.text).To(Equal("Wolf"), "the exact match should be first")
+				Expect(results[0].Matches[0].Distance).To(BeZero(), "the exact match should not be distant")
+				for resultIndex, result := range results {
+					Expect(result.Matches[0].Distance).To(BeNumerically("~", 1-similarity.JaroWinkler("WOLF", result.Matches[0].KeyTerm)),
+						"the distance of '%s' should be its Jaro-Winkler distance", result.Result.text)
+					Expect(result.Similarity).To(BeNumerically("~", similarity.JaroWinkler("WOLF", result.Matches[0].KeyTerm)),
+						"the similarity of '%s' should be its Jaro-Winkler similarity", result.Result.text)
+					if resultIndex > 0 {
+						Expect(result.Matches[0].Distance).To(BeNumerically(">=", results[resultIndex-1].Matches[0].Distance),
+							"'%s' should be ranked by its Jaro-Winkler distance", result.Result.text)
+					}
+				}
+			})
+
+			It("should not give similarities for metrics that do not convert their distances", func() {
+				tree.SetMetric(trie.MetricFunc(func(searchTerm string, keyTerm string) float64 {
+					return 0.25
+				}))
+
+				results, err := tree.Search(ctx, "cat")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).ToNot(BeEmpty(), "results should be returned")
+				for _, result := range results {
+					Expect(result.Similarity).To(BeZero(), "the similarity of '%s' should not be derived from its distance", result.Result.text)
+				}
+			})
+
+			It("should measure each tree with its own metric", func() {
+				cat := newTestComparableFuzzable("Cat")
+				catfish := newTestComparableFuzzable("Catfish")
+				items := []*testComparableFuzzable{cat, catfish}
+				firstTree := loadTestTree(ctx, items)
+				secondTree := loadTestTree(ctx, items)
+
+				// Measures the fraction of the key term not covered by the search term
+				coverageMetric := trie.MetricFunc(func(searchTerm string, keyTerm string) float64 {
+					return 1 - float64(len(searchTerm))/float64(len(keyTerm))
+				})
+
+				results, err := newDistanceTreesWithWeights([]*trie.WeightedTree[*testComparableFuzzable]{
+					{Tree: firstTree, Weight: 1},
+					{Tree: secondTree, Weight: 1, Metric: coverageMetric},
+				}).SearchWithOptions(ctx, "cat", trie.SearchOptions{})
+				Expect(err).ToNot(HaveOccurred(), "searching the trees should not fail")
+				Expect(results).To(HaveLen(2), "both items should be returned")
+				Expect(results[1].Result).To(Equal(catfish), "the further item should be last")
+				Expect(*results[1].Distances[0]).To(Equal(4), "the first tree should use the default metric")
+				Expect(*results[1].Distances[1]).To(Equal(0), "the second tree's fractional distance should be truncated")
+				Expect(results[1].Score).To(BeNumerically("~", 1.0/5+1/(1+4.0/7)), "the score should use the unrounded distances")
+			})
+
+			It("should stop measuring key terms at the maximum distance with bounded metrics", func() {
+				boundedMetric := &testBoundedMetric{}
+				tree.SetMetric(boundedMetric)
+
+				_, err := tree.Search(ctx, "cat")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(boundedMetric.maxDistances).To(BeEmpty(), "the distances should not be bounded without a maximum distance")
+
+				maxDistance := 2
+				results, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{MaxDistance: &maxDistance})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(boundedMetric.maxDistances).ToNot(BeEmpty(), "the distances should be bounded with a maximum distance")
+				Expect(boundedMetric.maxDistances).To(HaveEach(maxDistance), "the distances should be bounded by the maximum distance")
+				Expect(results).ToNot(BeEmpty(), "results should be returned")
+				for _, result := range results {
+					Expect(result.Matches[0].Distance).To(BeNumerically("<=", maxDistance), "'%s' should be within the maximum distance", result.Result.text)
+				}
+			})
+
+			It("should measure fractional distances with edit costs", func() {
+				btc := newTestComparableFuzzable("BTC")
+				btv := newTestComparableFuzzable("BTV")
+				btz := newTestComparableFuzzable("BTZ")
+				typoTree := loadTestTree(ctx, []*testComparableFuzzable{btz, btv, btc})
+
+				maxDistance := 1
+				results, err := newDistanceTreesWithWeights([]*trie.WeightedTree[*testComparableFuzzable]{
+					{Tree: typoTree, Weight: 1, Metric: trie.NewEditCostsMetric(levenshtein.TypoCosts)},
+				}).SearchWithOptions(ctx, "btc", trie.SearchOptions{MatchMode: trie.MatchModeFuzzy, MaxDistance: &maxDistance})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(HaveLen(3), "every item should be returned")
+				Expect(results[0].Result).To(Equal(btc), "the exact match should be first")
+				Expect(results[1].Result).To(Equal(btv), "the typo on an adjacent key should be closer than the typo on a distant key")
+				Expect(results[1].Matches[0].Distance).To(BeNumerically("~", 0.5), "the fractional distance should be returned")
+				Expect(results[1].Score).To(BeNumerically("~", 1/1.5), "the score should use the fractional distance")
+				Expect(results[2].Result).To(Equal(btz), "the typo on a distant key should be last")
+			})
+
+			It("should find typos within the maximum distance with edit costs", func() {
+				btc := newTestComparableFuzzable("BTC")
+				eth := newTestComparableFuzzable("ETH")
+				typoTree := loadTestTree(ctx, []*testComparableFuzzable{btc, eth})
+
+				typoTrees := newDistanceTreesWithWeights([]*trie.WeightedTree[*testComparableFuzzable]{
+					{Tree: typoTree, Weight: 1, Metric: trie.NewEditCostsMetric(levenshtein.TypoCosts)},
+				})
+
+				maxDistance := 1
+				for _, searchTerm := range []string{"vyc", "tbc", "btv"} {
+					results, err := typoTrees.SearchWithOptions(ctx, searchTerm, trie.SearchOptions{MatchMode: trie.MatchModeFuzzy, MaxDistance: &maxDistance})
+					Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+					Expect(results).To(HaveLen(1), "only the typo of '%s' should be returned", searchTerm)
+					Expect(results[0].Result).To(Equal(btc), "the typo of '%s' should be found", searchTerm)
+					Expect(results[0].Matches[0].Distance).To(BeNumerically("<=", maxDistance), "the typo of '%s' should be within the maximum distance", searchTerm)
+				}
+
+				results, err := typoTrees.SearchWithOptions(ctx, "tbc", trie.SearchOptions{MaxDistance: &maxDistance})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(HaveLen(1), "the transposed characters should match by default")
+				Expect(results[0].Result).To(Equal(btc), "the transposition should be found")
+			})
+
+			It("should not skip key terms for metrics that are not bounded by length", func() {
+				maxDistance := 0
+				tree.SetMetric(trie.MetricFunc(func(searchTerm string, keyTerm string) float64 {
+					return 0
+				}))
+
+				results, err := tree.SearchWithOptions(ctx, "cat", trie.SearchOptions{MaxDistance: &maxDistance})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(HaveLen(21), "every matching key term should be measured")
+			})
+		})
+
+		Context("sorting groups", func() {
+			var groupedTree *trie.DistanceTrees[*testComparableFuzzable]
+
+			BeforeEach(func() {
+				// Place domesticated animals in a sorting group ahead of all others
+				animals := newTestAnimals()
+				for _, animal := range animals {
+					if strings.HasPrefix(animal.text, "Domestic") {
+						animal.group = 0
+					}
+				}
+
+				animalsTree := loadTestTree(ctx, animals)
+				groupedTree = trie.NewDistanceTrees[*testComparableFuzzable]([]*trie.Tree[*testComparableFuzzable]{animalsTree})
+			})
+
+			resultGroups := func(results []*trie.DistanceResult[*testComparableFuzzable]) []int {
+				groups := make([]int, len(results))
+				for i, result := range results {
+					groups[i] = result.Result.group
+				}
+				return groups
+			}
+
+			It("should rank lower sorting groups first", func() {
+				results, err := groupedTree.Search(ctx, "cat")
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(HaveLen(21), "the correct number of results should be returned")
+				Expect(resultGroups(results)).To(Equal([]int{0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}), "the domesticated animals should be ranked first")
+				Expect(results[0].Result.text).To(Equal("Domestic goat"), "the closest domesticated animal should be first")
+				Expect(results[3].Result.text).To(Equal("Cat"), "the closest other animal should follow the domesticated animals")
+			})
+
+			It("should interleave sorting groups", func() {
+				results, err := groupedTree.SearchWithOptions(ctx, "cat", trie.SearchOptions{GroupOrdering: trie.GroupOrderingInterleaved})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(HaveLen(21), "the correct number of results should be returned")
+				Expect(resultGroups(results)[:7]).To(Equal([]int{0, 1, 0, 1, 0, 1, 1}), "the groups should alternate until the domesticated animals are exhausted")
+				Expect(results[0].Result.text).To(Equal("Domestic goat"), "the closest domesticated animal should be first")
+				Expect(results[1].Result.text).To(Equal("Cat"), "the closest other animal should be second")
+			})
+
+			It("should explain the ranking by sorting group", func() {
+				results, err := groupedTree.SearchWithOptions(ctx, "cat", trie.SearchOptions{Explain: true})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results[3].Explanation.RankedBy).To(Equal(trie.RankingStepSortingGroup), "the first result of the next group should be ranked by group")
+
+				results, err = groupedTree.SearchWithOptions(ctx, "cat", trie.SearchOptions{Explain: true, GroupOrdering: trie.GroupOrderingInterleaved})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results[1].Explanation.RankedBy).To(Equal(trie.RankingStepGroupInterleaving), "alternating results should be ranked by interleaving")
+				Expect(results[6].Explanation.RankedBy).To(Equal(trie.RankingStepDistance), "results of the same group should be ranked by distance")
+			})
+
+			It("should ignore sorting groups", func() {
+				results, err := groupedTree.SearchWithOptions(ctx, "cat", trie.SearchOptions{GroupOrdering: trie.GroupOrderingIgnored})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results[0].Result.text).To(Equal("Cat"), "the closest animal should be first")
+				Expect(results[20].Result.text).To(Equal("Domestic Bactrian camel"), "the furthest animal should be last")
+			})
+
+			It("should cap the number of results per sorting group", func() {
+				results, err := groupedTree.SearchWithOptions(ctx, "cat", trie.SearchOptions{MaxResultsPerGroup: 2})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(resultGroups(results)).To(Equal([]int{0, 0, 1, 1}), "each group should be capped")
+				Expect(results[2].Result.text).To(Equal("Cat"), "the best of each group should be kept")
+
+				results, err = groupedTree.SearchWithOptions(ctx, "cat", trie.SearchOptions{MaxResultsPerGroup: 2, Limit: 1, Offset: 2})
+				Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+				Expect(results).To(HaveLen(1), "the limit should apply to the capped results")
+				Expect(results[0].Result.text).To(Equal("Cat"), "the offset should apply to the capped results")
+			})
+		})
 	})
 })
 
+// testBoundedMetric measures Levenshtein distances, recording the maximum distance of each bounded measurement.
+type testBoundedMetric struct {
+	maxDistances []int
+}
+
+func (m *testBoundedMetric) Distance(searchTerm string, keyTerm string) float64 {
+	return float64(levenshtein.LevenshteinDistance(searchTerm, keyTerm))
+}
+
+func (m *testBoundedMetric) BoundedDistance(searchTerm string, keyTerm string, maxDistance int) (float64, bool) {
+	m.maxDistances = append(m.maxDistances, maxDistance)
+	distance, isWithin := levenshtein.BoundedDistance(searchTerm, keyTerm, maxDistance)
+	return float64(distance), isWithin
+}
+
 type testComparableFuzzable struct {
-	text string
+	text                  string
+	group                 int
+	primaryDistanceFactor *float64
 }
 
 func newTestComparableFuzzable(text string) *testComparableFuzzable {
 	return &testComparableFuzzable{
-		text: text,
+		text:  text,
+		group: 1,
 	}
 }
 
 func (t *testComparableFuzzable) GetPrimaryDistanceFactor() *float64 {
-	return nil
+	return t.primaryDistanceFactor
 }
 
 func (t *testComparableFuzzable) GetSecondaryDistances() []*int {
@@ -96,5 +933,5 @@ func (t *testComparableFuzzable) GetSecondaryDistances() []*int {
 }
 
 func (t *testComparableFuzzable) SortingGroup() int {
-	return 1
+	return t.group
 }
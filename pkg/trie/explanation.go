@@ -0,0 +1,131 @@
+package trie
+
+// RankingStep identifies the step of ranking that decided the order of a result relative to the result before it.
+type RankingStep int
+
+const (
+	// RankingStepNone denotes that the result is the first result, or is ranked equally to the result before it by all
+	// the steps below, and so only follows it by their unrounded distances, their key terms or the order in which their
+	// items were loaded.
+	RankingStepNone RankingStep = iota
+	// RankingStepSortingGroup denotes that the result is in a higher sorting group than the result before it.
+	RankingStepSortingGroup
+	// RankingStepGroupInterleaving denotes that the result follows the result before it because GroupOrderingInterleaved
+	// takes the results of each sorting group in turn.
+	RankingStepGroupInterleaving
+	// RankingStepScore denotes that the result has a lower Score than the result before it.
+	RankingStepScore
+	// RankingStepDistance denotes that the result has a greater distance than the result before it; the distance that
+	// decided the order is identified by Explanation.RankingDistanceIndex.
+	RankingStepDistance
+)
+
+// Explanation is a breakdown of how a DistanceResult was scored and ranked, as returned when SearchOptions.Explain is set.
+type Explanation struct {
+	// Trees holds the breakdown of the result's match in each tree, in the order of the trees.
+	// A tree's breakdown is nil if the result did not match the search term in that tree.
+	Trees []*TreeExplanation
+	// SecondaryDistances holds the secondary distances of the result, which are the last of its Distances.
+	SecondaryDistances []*int
+	// SortingGroup is the sorting group of the result.
+	SortingGroup int
+	// Score is the combined score of the result, which is the sum of the scores of the result in each tree.
+	Score float64
+	// RankedBy is the step of ranking that decided the order of the result relative to the result before it.
+	RankedBy RankingStep
+	// RankingDistanceIndex is the index within Distances of the distance that decided the order of the result relative
+	// to the result before it, if RankedBy is RankingStepDistance; otherwise, this is -1.
+	RankingDistanceIndex int
+}
+
+// TreeExplanation is a breakdown of how a result matched in one tree.
+type TreeExplanation struct {
+	// TreeIndex is the index of the tree among the trees searched.
+	TreeIndex int
+	// TreeName is the name given to the tree with WithTreeName, if any.
+	TreeName string
+	// KeyTerm is the normalized key term under which the result was matched.
+	KeyTerm string
+	// RawDistance is the distance of KeyTerm from the normalized search term, as measured by the tree's Metric.
+	// In a tree loaded with WithTokenizer, this includes the penalty for the position of KeyTerm within the matched term.
+	// For searches with multiple query terms, this is the combined distance of all the query terms.
+	RawDistance float64
+	// PrimaryDistanceFactor is the primary distance factor of the result, if any, by which RawDistance was scaled.
+	PrimaryDistanceFactor *float64
+	// ScaledDistance is RawDistance scaled by the PrimaryDistanceFactor; it is rounded down into the result's Distances,
+	// where it is offset by 10^TreeIndex for a DistanceTrees instance built with NewDistanceTrees.
+	ScaledDistance float64
+	// Weight is the weight of the tree.
+	Weight float64
+	// Score is the contribution of this tree to the result's Score: the Weight multiplied by the score of the
+	// ScaledDistance as given by the tree's Scorer.
+	Score float64
+}
+
+// explainResult builds the Explanation of how the given DistanceResult, with the given secondary distances, was scored
+// once its Score, Distances and sortingGroup have been calculated; its ranking is explained separately by explainRanking.
+func (wt *DistanceTrees[T]) explainResult(result *DistanceResult[T], secondaryDistances []*int) *Explanation {
+	explanation := &Explanation{
+		Trees:                make([]*TreeExplanation, len(wt.trees)),
+		SecondaryDistances:   secondaryDistances,
+		SortingGroup:         result.sortingGroup,
+		Score:                result.Score,
+		RankingDistanceIndex: -1,
+	}
+
+	for treeIndex, weightedTree := range wt.trees {
+		treeMatch := result.Matches[treeIndex]
+		if treeMatch == nil {
+			continue
+		}
+
+		scaledDistance := result.treeDistances[treeIndex]
+		rawDistance := treeMatch.Distance
+		if result.rawTreeDistances != nil {
+			rawDistance = result.rawTreeDistances[treeIndex]
+		}
+		explanation.Trees[treeIndex] = &TreeExplanation{
+			TreeIndex:             treeIndex,
+			TreeName:              treeMatch.TreeName,
+			KeyTerm:               treeMatch.KeyTerm,
+			RawDistance:           rawDistance,
+			PrimaryDistanceFactor: result.Result.GetPrimaryDistanceFactor(),
+			ScaledDistance:        scaledDistance,
+			Weight:                weightedTree.Weight,
+			Score:                 weightedTree.score(scaledDistance),
+		}
+	}
+
+	return explanation
+}
+
+// explainRanking records, in the Explanation of each of the given ranked DistanceResult objects from the given index
+// onwards, the step of ranking that decided its order relative to the result before it.
+func explainRanking[T any](rankedResults []*DistanceResult[T], fromIndex int, options SearchOptions, rankByScore bool) {
+	for resultIndex := max(fromIndex, 1); resultIndex < len(rankedResults); resultIndex++ {
+		result, previousResult := rankedResults[resultIndex], rankedResults[resultIndex-1]
+		result.Explanation.RankedBy, result.Explanation.RankingDistanceIndex = getRankingStep(previousResult, result, options, rankByScore)
+	}
+}
+
+// getRankingStep determines the step of ranking that placed the given result after the given previous result, along
+// with the index of the deciding distance if that step is RankingStepDistance, or -1 otherwise.
+// This follows the same steps as the comparators used by rankResults.
+func getRankingStep[T any](previousResult *DistanceResult[T], result *DistanceResult[T], options SearchOptions, rankByScore bool) (RankingStep, int) {
+	if options.GroupOrdering != GroupOrderingIgnored && compareSortingGroups(previousResult, result) != 0 {
+		if options.GroupOrdering == GroupOrderingInterleaved {
+			return RankingStepGroupInterleaving, -1
+		}
+		return RankingStepSortingGroup, -1
+	}
+
+	if rankByScore && compareScores(previousResult, result) != 0 {
+		return RankingStepScore, -1
+	}
+
+	if comparison, distanceIndex := compareDistances(previousResult, result); comparison != 0 {
+		return RankingStepDistance, distanceIndex
+	}
+
+	return RankingStepNone, -1
+}
@@ -11,5 +11,7 @@ type Fuzzable interface {
 
 	// SortingGroup gets the zero-based index of the sorting group for purposes of determining rank of an asset relative to others.
 	// This allows members within a particular sorting group to be ranked relative to each other.
+	// By default, members of lower-indexed sorting groups are ranked ahead of members of higher-indexed sorting groups;
+	// see SearchOptions.GroupOrdering for alternatives.
 	SortingGroup() int
 }
@@ -0,0 +1,116 @@
+package trie
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Index is a concurrency-safe wrapper around a DistanceTrees instance.
+// It allows any number of concurrent searches alongside writers that modify or replace the underlying trees.
+// Searches share a read lock, while modifications and replacements take an exclusive lock, so a write waits for
+// in-flight searches to complete and searches started after a write observe all of its changes.
+type Index[T ComparableFuzzable] struct {
+	mutex         sync.RWMutex
+	distanceTrees *DistanceTrees[T]
+}
+
+// NewIndex builds an Index that serves searches from the given DistanceTrees.
+// The given DistanceTrees, and the trees within it, should not be used directly once handed to the index.
+func NewIndex[T ComparableFuzzable](distanceTrees *DistanceTrees[T]) *Index[T] {
+	return &Index[T]{
+		distanceTrees: distanceTrees,
+	}
+}
+
+// Search searches the trees within this index for the given search term.
+// See DistanceTrees.Search for more details.
+func (i *Index[T]) Search(ctx context.Context, searchTerm string) ([]*DistanceResult[T], error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	return i.distanceTrees.Search(ctx, searchTerm)
+}
+
+// SearchWithOptions searches the trees within this index for the given search term using the given options.
+// See DistanceTrees.SearchWithOptions for more details.
+func (i *Index[T]) SearchWithOptions(ctx context.Context, searchTerm string, options SearchOptions) ([]*DistanceResult[T], error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	return i.distanceTrees.SearchWithOptions(ctx, searchTerm, options)
+}
+
+// Insert inserts the given item into every tree within this index.
+// The terms of the item are extracted from every tree before any tree is changed, so if the insertion fails for any
+// tree, no tree is changed.
+func (i *Index[T]) Insert(ctx context.Context, item T) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.updateTrees("failed to insert into tree at index %d: %w", func(tree *Tree[T]) (*treeUpdate[T], error) {
+		return tree.prepareInsert(ctx, item)
+	})
+}
+
+// Remove removes the given item from every tree within this index.
+// The item is found in every tree before any tree is changed, so if it cannot be found in any of them, or its terms
+// cannot be extracted, no tree is changed.
+func (i *Index[T]) Remove(ctx context.Context, item T) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.updateTrees("failed to remove from tree at index %d: %w", func(tree *Tree[T]) (*treeUpdate[T], error) {
+		return tree.prepareRemove(ctx, item)
+	})
+}
+
+// Update replaces the given old item with the given new item in every tree within this index.
+// The change is applied atomically with respect to searches: no search will observe the old item removed but the
+// new item not yet inserted. The terms of both items are extracted from every tree before any tree is changed, so if
+// the update fails for any tree, e.g., because the old item is not in it, no tree is changed.
+func (i *Index[T]) Update(ctx context.Context, oldItem T, newItem T) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.updateTrees("failed to update tree at index %d: %w", func(tree *Tree[T]) (*treeUpdate[T], error) {
+		return tree.prepareUpdate(ctx, oldItem, newItem)
+	})
+}
+
+// updateTrees prepares an update of every tree within this index with the given function, and applies the updates
+// only if every one of them could be prepared. Otherwise, the errors of every tree that failed are returned, each
+// formatted with the given format along with the index of the tree.
+// The caller must hold the write lock.
+func (i *Index[T]) updateTrees(errFormat string, prepareUpdate func(tree *Tree[T]) (*treeUpdate[T], error)) error {
+	var updateErrs []error
+	updates := make([]*treeUpdate[T], 0, len(i.distanceTrees.trees))
+	for treeIndex, tree := range i.distanceTrees.trees {
+		update, updateErr := prepareUpdate(tree.Tree)
+		if updateErr != nil {
+			updateErrs = append(updateErrs, fmt.Errorf(errFormat, treeIndex, updateErr))
+			continue
+		}
+		updates = append(updates, update)
+	}
+
+	if len(updateErrs) > 0 {
+		return errors.Join(updateErrs...)
+	}
+
+	for _, update := range updates {
+		update.apply()
+	}
+
+	return nil
+}
+
+// Replace swaps the DistanceTrees served by this index with the given one, e.g., after rebuilding the trees offline.
+// The swap waits for searches in flight to complete against the previous trees.
+func (i *Index[T]) Replace(distanceTrees *DistanceTrees[T]) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.distanceTrees = distanceTrees
+}
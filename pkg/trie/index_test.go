@@ -0,0 +1,137 @@
+package trie_test
+
+import (
+	"context"
+	"errors"
+	"github.com/coinbase/fuzzy-trie/pkg/trie"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"strings"
+	"sync"
+)
+
+var _ = Describe("Index", func() {
+	var ctx context.Context
+	var animals []*testComparableFuzzable
+
+	loadDistanceTrees := func(items []*testComparableFuzzable) *trie.DistanceTrees[*testComparableFuzzable] {
+		return trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{loadTestTree(ctx, items)})
+	}
+
+	BeforeEach(func() {
+		ctx = newTestContext()
+		animals = newTestAnimals()
+	})
+
+	It("should reflect modifications in subsequent searches", func() {
+		index := trie.NewIndex(loadDistanceTrees(animals))
+
+		unicorn := newTestComparableFuzzable("Unicorn")
+		Expect(index.Insert(ctx, unicorn)).To(Succeed(), "inserting should not fail")
+		results, err := index.Search(ctx, "unicorn")
+		Expect(err).ToNot(HaveOccurred(), "searching should not fail")
+		Expect(results).To(HaveLen(1), "the inserted item should be found")
+		Expect(results[0].Result).To(Equal(unicorn), "the inserted item should be returned")
+
+		narwhal := newTestComparableFuzzable("Narwhal")
+		Expect(index.Update(ctx, unicorn, narwhal)).To(Succeed(), "updating should not fail")
+		results, err = index.Search(ctx, "unicorn")
+		Expect(err).ToNot(HaveOccurred(), "searching should not fail")
+		Expect(results).To(BeEmpty(), "the updated item should no longer be found by its old term")
+
+		Expect(index.Remove(ctx, narwhal)).To(Succeed(), "removing should not fail")
+		Expect(index.Remove(ctx, narwhal)).To(MatchError(trie.ErrItemNotFound), "removing twice should fail")
+
+		index.Replace(loadDistanceTrees([]*testComparableFuzzable{unicorn}))
+		results, err = index.Search(ctx, "cat")
+		Expect(err).ToNot(HaveOccurred(), "searching should not fail")
+		Expect(results).To(BeEmpty(), "the replaced trees should no longer be searched")
+	})
+
+	It("should leave every tree unchanged when a modification fails for any of them", func() {
+		cat := newTestComparableFuzzable("Cat")
+		nameTree := loadTestTree(ctx, []*testComparableFuzzable{cat})
+		strictTree, err := trie.LoadTree(ctx, []*testComparableFuzzable{cat}, func(_ context.Context, item *testComparableFuzzable) (string, error) {
+			if item.text == "" {
+				return "", errors.New("no text")
+			}
+			return item.text, nil
+		})
+		Expect(err).ToNot(HaveOccurred(), "loading the strict tree should not fail")
+		index := trie.NewIndex(trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{nameTree, strictTree}))
+
+		Expect(index.Update(ctx, cat, newTestComparableFuzzable(""))).ToNot(Succeed(), "updating to an item without terms should fail")
+		results, err := index.Search(ctx, "cat")
+		Expect(err).ToNot(HaveOccurred(), "searching should not fail")
+		Expect(results).To(HaveLen(1), "the old item should still be found")
+		Expect(results[0].Distances[0]).ToNot(BeNil(), "the old item should still be in the first tree")
+		Expect(results[0].Distances[1]).ToNot(BeNil(), "the old item should still be in the second tree")
+
+		Expect(index.Update(ctx, newTestComparableFuzzable("Dog"), newTestComparableFuzzable("Cow"))).To(MatchError(trie.ErrItemNotFound), "updating an absent item should fail")
+		results, err = index.Search(ctx, "cow")
+		Expect(err).ToNot(HaveOccurred(), "searching should not fail")
+		Expect(results).To(BeEmpty(), "the new item should not have been inserted")
+
+		Expect(index.Insert(ctx, newTestComparableFuzzable(""))).ToNot(Succeed(), "inserting an item without terms should fail")
+		Expect(nameTree.Root().GetValues()).To(BeEmpty(), "the item should not have been inserted into the first tree")
+
+		cow := newTestComparableFuzzable("Cow")
+		Expect(nameTree.Insert(ctx, cow)).To(Succeed(), "inserting into the first tree alone should not fail")
+		Expect(index.Remove(ctx, cow)).To(MatchError(trie.ErrItemNotFound), "removing an item missing from a tree should fail")
+		results, err = index.Search(ctx, "cow")
+		Expect(err).ToNot(HaveOccurred(), "searching should not fail")
+		Expect(results).To(HaveLen(1), "the item should not have been removed from the first tree")
+	})
+
+	It("should serve concurrent searches during modifications and replacements", func() {
+		index := trie.NewIndex(loadDistanceTrees(animals[:len(animals)/2]))
+		replacementTrees := loadDistanceTrees(animals)
+
+		stopChan := make(chan struct{})
+		var searchers sync.WaitGroup
+		for searcherIndex := 0; searcherIndex < 8; searcherIndex++ {
+			searchers.Add(1)
+			go func(searchTerm string) {
+				defer GinkgoRecover()
+				defer searchers.Done()
+				for {
+					select {
+					case <-stopChan:
+						return
+					default:
+					}
+
+					results, err := index.Search(ctx, searchTerm)
+					Expect(err).ToNot(HaveOccurred(), "searching should not fail")
+					for _, result := range results {
+						Expect(result.Result).ToNot(BeNil(), "no result should be nil")
+					}
+				}
+			}([]string{"cat", "wol", "e", "dom"}[searcherIndex%4])
+		}
+
+		for _, animal := range animals[len(animals)/2:] {
+			Expect(index.Insert(ctx, animal)).To(Succeed(), "inserting '%s' should not fail", animal.text)
+		}
+		for _, animal := range animals[:len(animals)/4] {
+			Expect(index.Remove(ctx, animal)).To(Succeed(), "removing '%s' should not fail", animal.text)
+		}
+		index.Replace(replacementTrees)
+		for _, animal := range animals[:len(animals)/4] {
+			Expect(index.Update(ctx, animal, newTestComparableFuzzable(animal.text+" (renamed)"))).To(Succeed(), "updating '%s' should not fail", animal.text)
+		}
+
+		close(stopChan)
+		searchers.Wait()
+
+		results, err := index.Search(ctx, "renamed")
+		Expect(err).ToNot(HaveOccurred(), "searching should not fail")
+		renamedCount := 0
+		for _, result := range results {
+			if strings.HasSuffix(result.Result.text, " (renamed)") {
+				renamedCount++
+			}
+		}
+		Expect(renamedCount).To(Equal(len(animals)/4), "all the updated items should be found")
+	})
+})
@@ -0,0 +1,50 @@
+package trie
+
+// LoadOption configures how a Tree is loaded.
+type LoadOption func(options *loadOptions)
+
+// loadOptions holds the configuration of a Tree being loaded.
+type loadOptions struct {
+	normalizer           Normalizer
+	name                 string
+	tokenizer            Tokenizer
+	tokenPositionPenalty float64
+}
+
+// WithNormalizer sets the Normalizer used to normalize the key terms of the tree and the terms it is searched for.
+// If not given, UpperCaseNormalizer is used.
+func WithNormalizer(normalizer Normalizer) LoadOption {
+	return func(options *loadOptions) {
+		options.normalizer = normalizer
+	}
+}
+
+// WithTreeName names the tree, such that the matches of search results within it can be identified by name.
+func WithTreeName(name string) LoadOption {
+	return func(options *loadOptions) {
+		options.name = name
+	}
+}
+
+// WithTokenizer splits the terms of the tree's items into tokens with the given tokenizer, placing each item under the
+// key terms beginning at each of its tokens so that the words within a term can be matched individually.
+// The distance of a key term from a search term is then measured against as many of the key term's leading tokens as
+// the search term has, adding the given penalty for each token preceding the key term in the item's term, such that
+// matches further into a term are ranked lower.
+func WithTokenizer(tokenizer Tokenizer, positionPenalty float64) LoadOption {
+	return func(options *loadOptions) {
+		options.tokenizer = tokenizer
+		options.tokenPositionPenalty = positionPenalty
+	}
+}
+
+// buildLoadOptions builds the configuration described by the given options, applying defaults for anything not configured.
+func buildLoadOptions(options []LoadOption) *loadOptions {
+	builtOptions := &loadOptions{
+		normalizer: UpperCaseNormalizer,
+	}
+	for _, option := range options {
+		option(builtOptions)
+	}
+	return builtOptions
+}
@@ -0,0 +1,64 @@
+package trie
+
+import (
+	"unicode/utf8"
+)
+
+// TreeMatch describes how a result matched the search term within one tree.
+type TreeMatch struct {
+	// TreeIndex is the index of the tree among the trees searched.
+	TreeIndex int
+	// TreeName is the name given to the tree with WithTreeName, if any.
+	TreeName string
+	// KeyTerm is the normalized key term under which the result was matched.
+	KeyTerm string
+	// Positions holds the offsets, in runes, of the characters of the normalized search term within KeyTerm, in the order
+	// of the search term, which can be used to highlight the matched characters.
+	// Unless the tree's Normalizer changes the number of runes in a term (e.g., by collapsing separators), these offsets
+	// also apply to the term from which the key term was extracted.
+	// With MatchModeFuzzyPrefix and MatchModeFuzzy, the search term need not contain the characters of the key term, so
+	// Positions instead holds the offsets of the runes of the prefix of KeyTerm that is closest to the search term.
+	Positions []int
+	// Distance is the distance of KeyTerm from the normalized search term, as measured by the tree's Metric, before it
+	// was scaled by the result's primary distance factor or offset by the index of the tree.
+	// In a tree loaded with WithTokenizer, this includes the penalty for the TokenPosition of the match.
+	Distance float64
+	// Similarity is the similarity of KeyTerm to the normalized search term, from 0 to 1, as given by the tree's Metric if
+	// it implements SimilarityMetric, or 0 otherwise. For edit distances, such as those of LevenshteinMetric, this is the
+	// proportion of the runes of the longer of the two terms that the distance leaves unedited (see
+	// similarity.NormalizeDistance); for JaroWinklerMetric, it is their Jaro-Winkler similarity. Unlike Distance, this
+	// means the same thing for short and long key terms, and does not include any token position penalty.
+	// In a tree loaded with WithTokenizer, only as many leading tokens of KeyTerm as the search term has are compared.
+	Similarity float64
+	// TokenPosition is the position of the leading token of KeyTerm within the term from which it was built, if the tree
+	// was loaded with WithTokenizer; otherwise, this is zero.
+	TokenPosition int
+}
+
+// newTreeMatch builds a TreeMatch for the given key term, at the given distance, similarity and token position in the
+// given tree, from the offsets of the matched runes from the end of the key term, as populated by Node.matchRunes.
+func newTreeMatch[T any](
+	treeIndex int,
+	tree *Tree[T],
+	keyTerm string,
+	distance float64,
+	similarity float64,
+	tokenPosition int,
+	offsetsFromEnd []int,
+) *TreeMatch {
+	lastPosition := utf8.RuneCountInString(keyTerm) - 1
+	positions := make([]int, len(offsetsFromEnd))
+	for runeIndex, offsetFromEnd := range offsetsFromEnd {
+		positions[runeIndex] = lastPosition - offsetFromEnd
+	}
+
+	return &TreeMatch{
+		TreeIndex:     treeIndex,
+		TreeName:      tree.Name(),
+		KeyTerm:       keyTerm,
+		Positions:     positions,
+		Distance:      distance,
+		Similarity:    similarity,
+		TokenPosition: tokenPosition,
+	}
+}
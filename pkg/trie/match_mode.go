@@ -0,0 +1,354 @@
+package trie
+
+import (
+	"context"
+	"time"
+)
+
+// MatchMode determines how the key term of a node must contain the search term for the node to match it.
+// Whichever the mode, matching key terms are ranked by their distances from the search term.
+type MatchMode int
+
+const (
+	// MatchModeSubsequence matches key terms that contain the characters of the search term in order, even if not
+	// contiguously (e.g., "cal" matches "catapult"); this is the default.
+	MatchModeSubsequence MatchMode = iota
+	// MatchModeSubstring matches key terms that contain the search term contiguously (e.g., "pul" matches "catapult").
+	MatchModeSubstring
+	// MatchModePrefix matches key terms that begin with the search term (e.g., "cat" matches "catapult").
+	// Matches are found by walking down from the root of the tree to the search term, rather than up from every leaf.
+	MatchModePrefix
+	// MatchModeExact matches key terms identical to the search term, walking down from the root of the tree.
+	MatchModeExact
+	// MatchModeFuzzyPrefix matches key terms that begin with a prefix no more than SearchOptions.PrefixEditBudget edits
+	// away from the search term (e.g., "cst" matches "catapult" with a budget of 1), walking down from the root of the
+	// tree and abandoning branches as soon as none of their prefixes can be within the budget.
+	MatchModeFuzzyPrefix
+	// MatchModeFuzzy matches key terms whose distance from the search term, as measured by the tree's Metric, is within
+	// the maximum distance of the search, regardless of whether they contain its characters (e.g., "cst" matches "cat"
+	// with a maximum distance of 1). Matches are found by walking down from the root of the tree while carrying a row of
+	// the Levenshtein distance matrix for each node, abandoning whole branches as soon as none of their key terms can be
+	// within the Levenshtein distance allowed by the metric (see LevenshteinBoundedMetric), which keeps the cost of a
+	// search in proportion to the branches close to the search term rather than to the size of the tree. Without a
+	// maximum distance, or with a metric that does not bound the Levenshtein distance, every key term is measured.
+	MatchModeFuzzy
+)
+
+// isValid determines if this is one of the defined match modes.
+func (m MatchMode) isValid() bool {
+	return m >= MatchModeSubsequence && m <= MatchModeFuzzy
+}
+
+// isTopDown determines if key terms are matched in this mode by walking down from the root of a tree.
+func (m MatchMode) isTopDown() bool {
+	return m == MatchModePrefix || m == MatchModeExact || m == MatchModeFuzzyPrefix || m == MatchModeFuzzy
+}
+
+// matchesNode determines if the key term of the given node matches the search term in the given search's bottom-up
+// match mode, populating the search's match offsets if so.
+func matchesNode[T any](search *treeSearch, node *Node[T]) bool {
+	if search.matchMode == MatchModeSubstring {
+		return node.matchContiguousRunes(search.query.runes, search.matchOffsets)
+	}
+	if search.transpositionStates != nil {
+		return node.matchTransposedRunes(search.query.runes, search.matchOffsets, search.transpositionStates)
+	}
+	return node.matchRunes(search.query.runes, search.matchOffsets)
+}
+
+// transpositionState is a state of matchTransposedRunes, identified by its index: the number of trailing runes of the
+// search term matched so far, times two, plus one if the rune before them has already been matched out of order, ahead
+// of the rune after it.
+type transpositionState struct {
+	// reachedAt is one more than the offset from the end of the key term at which the state was first reached, 0 for
+	// the initial state, or -1 if the state has not been reached
+	reachedAt int
+	// previousState is the index of the state from which the state was first reached
+	previousState int
+	// matchedIndex is the index of the search rune matched on reaching the state
+	matchedIndex int
+}
+
+// newTranspositionStates builds the states needed by matchTransposedRunes for a search term of the given number of runes.
+func newTranspositionStates(runeCount int) []transpositionState {
+	return make([]transpositionState, 2*(runeCount+1))
+}
+
+// matchTransposedRunes determines if the key term of this node contains the given normalized runes in the order given,
+// as matchRunes does, once any number of non-overlapping pairs of adjacent runes are transposed, using the given states,
+// built by newTranspositionStates, as scratch space.
+// Reading the key term from its end, each state records the first offset at which it can be reached, as reaching a
+// state earlier leaves more of the key term for the remaining runes. If the given offsets are non-nil, each rune's
+// offset is set to the number of runes between it and the end of the key term along the path to the final state.
+func (n *Node[T]) matchTransposedRunes(normalizedRunes []rune, offsetsFromEnd []int, states []transpositionState) bool {
+	runeCount := len(normalizedRunes)
+	finalState := 2 * runeCount
+	for stateIndex := range states {
+		states[stateIndex].reachedAt = -1
+	}
+	states[0].reachedAt = 0
+
+	reach := func(stateIndex int, previousState int, matchedIndex int, offsetFromEnd int) {
+		if states[stateIndex].reachedAt < 0 {
+			states[stateIndex] = transpositionState{reachedAt: offsetFromEnd + 1, previousState: previousState, matchedIndex: matchedIndex}
+		}
+	}
+
+	currentNode := n
+	for offsetFromEnd := 0; states[finalState].reachedAt < 0 && currentNode != nil && currentNode.keyRune != nil; offsetFromEnd++ {
+		keyRune := *currentNode.keyRune
+		// Only the states reached before this rune may advance on it
+		for stateIndex := finalState; stateIndex >= 0; stateIndex-- {
+			if reachedAt := states[stateIndex].reachedAt; reachedAt < 0 || reachedAt > offsetFromEnd {
+				continue
+			}
+
+			matchedCount := stateIndex / 2
+			nextIndex := runeCount - matchedCount - 1
+			if stateIndex%2 == 1 {
+				// The rune before the next one was matched out of order, so the next one must be matched before any other
+				if normalizedRunes[nextIndex] == keyRune {
+					reach(stateIndex+3, stateIndex, nextIndex, offsetFromEnd)
+				}
+				continue
+			}
+
+			if nextIndex >= 0 && normalizedRunes[nextIndex] == keyRune {
+				reach(stateIndex+2, stateIndex, nextIndex, offsetFromEnd)
+			} else if nextIndex >= 1 && normalizedRunes[nextIndex-1] == keyRune {
+				reach(stateIndex+1, stateIndex, nextIndex-1, offsetFromEnd)
+			}
+		}
+		currentNode = currentNode.parent
+	}
+
+	if states[finalState].reachedAt < 0 {
+		return false
+	}
+
+	if offsetsFromEnd != nil {
+		for stateIndex := finalState; stateIndex != 0; stateIndex = states[stateIndex].previousState {
+			offsetsFromEnd[states[stateIndex].matchedIndex] = states[stateIndex].reachedAt - 1
+		}
+	}
+	return true
+}
+
+// matchContiguousRunes determines if the key term of this node contains the given normalized runes contiguously.
+// If the given offsets are non-nil, they must be as long as the runes, and each rune's offset is set to the number of
+// runes between it and the end of the key term, matching the last occurrence of the runes.
+func (n *Node[T]) matchContiguousRunes(normalizedRunes []rune, offsetsFromEnd []int) bool {
+	// Try each position from the end of the key term as the end of an occurrence of the runes
+	for endNode, endOffset := n, 0; endNode != nil && endNode.keyRune != nil; endNode, endOffset = endNode.parent, endOffset+1 {
+		currentNode := endNode
+		runeIndex := len(normalizedRunes) - 1
+		for runeIndex >= 0 && currentNode != nil && currentNode.keyRune != nil && *currentNode.keyRune == normalizedRunes[runeIndex] {
+			currentNode = currentNode.parent
+			runeIndex--
+		}
+
+		if runeIndex < 0 {
+			for matchedIndex := range offsetsFromEnd {
+				offsetsFromEnd[matchedIndex] = endOffset + len(normalizedRunes) - 1 - matchedIndex
+			}
+			return true
+		}
+	}
+
+	return len(normalizedRunes) == 0
+}
+
+// prefixOffsets builds the offsets from the end of a key term of the given length of the runes of its prefix of the
+// given length, as expected by newTreeMatch.
+func prefixOffsets(keyTermLength int, prefixLength int) []int {
+	offsetsFromEnd := make([]int, prefixLength)
+	for runeIndex := range offsetsFromEnd {
+		offsetsFromEnd[runeIndex] = keyTermLength - 1 - runeIndex
+	}
+	return offsetsFromEnd
+}
+
+// searchTreeTopDown searches the given tree by walking down from its root in the search's top-down match mode,
+// populating the results into the given state's results map.
+func (wt *DistanceTrees[T]) searchTreeTopDown(ctx context.Context, tree *Tree[T], state *searchState[T], search *treeSearch) error {
+	switch search.matchMode {
+	case MatchModeFuzzyPrefix:
+		return wt.searchEditDistance(ctx, tree, state, search, search.prefixEditBudget, true)
+	case MatchModeFuzzy:
+		// Without a maximum distance, or a metric bounding the Levenshtein distance, every key term is measured
+		editBudget := getLevenshteinBudget(search.metric, search.maxDistance)
+		// The key terms of a tokenized tree are measured by their leading tokens, which are prefixes of the key terms
+		return wt.searchEditDistance(ctx, tree, state, search, editBudget, search.query.tokenCount > 0)
+	}
+
+	prefixNode := tree.root
+	for _, searchRune := range search.query.runes {
+		if prefixNode = prefixNode.children[searchRune]; prefixNode == nil {
+			return nil
+		}
+	}
+
+	if search.matchMode == MatchModeExact {
+		if len(prefixNode.values) > 0 {
+			wt.evaluateTopDownMatch(ctx, state, search, prefixNode, prefixOffsets(len(search.query.runes), len(search.query.runes)), 0)
+		}
+		return nil
+	}
+
+	// depthNode is a node awaiting evaluation along with the number of runes in its key term
+	type depthNode struct {
+		node  *Node[T]
+		depth int
+	}
+
+	// Every key term below the prefix node begins with the search term, so each of its descendants holding values matches
+	pendingNodes := []depthNode{{node: prefixNode, depth: len(search.query.runes)}}
+	for len(pendingNodes) > 0 {
+		if ctxErr := context.Cause(ctx); ctxErr != nil {
+			return ctxErr
+		}
+
+		current := pendingNodes[len(pendingNodes)-1]
+		pendingNodes = pendingNodes[:len(pendingNodes)-1]
+
+		if len(current.node.values) > 0 {
+			wt.evaluateTopDownMatch(ctx, state, search, current.node, prefixOffsets(current.depth, len(search.query.runes)), -1)
+		}
+
+		for _, childNode := range current.node.children {
+			pendingNodes = append(pendingNodes, depthNode{node: childNode, depth: current.depth + 1})
+		}
+	}
+
+	return nil
+}
+
+// searchEditDistance searches the given tree for key terms within the given edit budget of the search term, walking
+// down from the root while carrying the edit distances of each node's key term from each prefix of the search term,
+// and abandoning branches as soon as none of their descendants can come within the budget.
+// If matchPrefixes is set, key terms match if any of their prefixes is within the budget; otherwise, the key terms
+// themselves must be within it.
+func (wt *DistanceTrees[T]) searchEditDistance(
+	ctx context.Context,
+	tree *Tree[T],
+	state *searchState[T],
+	search *treeSearch,
+	editBudget int,
+	matchPrefixes bool,
+) error {
+	// editNode is a node awaiting evaluation along with its edit distances and its closest matching prefix so far
+	type editNode struct {
+		node  *Node[T]
+		depth int
+		// editRow holds the edit distances of the node's key term from each prefix of the search term, or nil if none of
+		// the node's descendants can match any more closely than its ancestors already have
+		editRow []int
+		// prefixDistance is the edit distance of the search term from the closest matching prefix of the node's key
+		// term, of prefixLength runes, or -1 if no prefix within the budget has been found
+		prefixDistance int
+		prefixLength   int
+	}
+
+	rootRow := make([]int, len(search.query.runes)+1)
+	for runeIndex := range rootRow {
+		rootRow[runeIndex] = runeIndex
+	}
+
+	// Each node is pushed with its own edit row, extended from its parent's row by the node's rune
+	pendingNodes := []editNode{{node: tree.root, editRow: rootRow, prefixDistance: -1}}
+	for len(pendingNodes) > 0 {
+		if ctxErr := context.Cause(ctx); ctxErr != nil {
+			return ctxErr
+		}
+
+		current := pendingNodes[len(pendingNodes)-1]
+		pendingNodes = pendingNodes[:len(pendingNodes)-1]
+
+		if current.editRow != nil {
+			// The distance from the whole search term is the last entry of the row
+			distance := current.editRow[len(current.editRow)-1]
+			if !matchPrefixes {
+				if distance <= editBudget && len(current.node.values) > 0 {
+					wt.evaluateTopDownMatch(ctx, state, search, current.node, prefixOffsets(current.depth, current.depth), float64(distance))
+				}
+			} else if distance <= editBudget && (current.prefixDistance < 0 || distance < current.prefixDistance) {
+				current.prefixDistance = distance
+				current.prefixLength = current.depth
+			}
+
+			// Once every entry exceeds the budget, no longer key term can come within it
+			if minEditDistance(current.editRow) > editBudget {
+				current.editRow = nil
+			}
+		}
+
+		if current.prefixDistance < 0 && current.editRow == nil {
+			continue
+		}
+
+		if current.prefixDistance >= 0 && len(current.node.values) > 0 {
+			wt.evaluateTopDownMatch(ctx, state, search, current.node, prefixOffsets(current.depth, current.prefixLength), -1)
+		}
+
+		for childRune, childNode := range current.node.children {
+			child := current
+			child.node = childNode
+			child.depth = current.depth + 1
+			if current.editRow != nil {
+				child.editRow = nextEditRow(current.editRow, search.query.runes, childRune)
+			}
+			pendingNodes = append(pendingNodes, child)
+		}
+	}
+
+	return nil
+}
+
+// evaluateTopDownMatch evaluates the given node, whose key term is known to match the search term, recording the time
+// spent doing so. The given Levenshtein distance of the node's key term from the search term is used if it is not
+// negative and the search's metric measures Levenshtein distances.
+func (wt *DistanceTrees[T]) evaluateTopDownMatch(
+	ctx context.Context,
+	state *searchState[T],
+	search *treeSearch,
+	node *Node[T],
+	offsetsFromEnd []int,
+	levenshteinDistance float64,
+) {
+	nodeSearchStart := time.Now()
+	defer func() {
+		_ = wt.timer.RecordNodeSearchIteration(ctx, time.Since(nodeSearchStart))
+	}()
+
+	knownDistance := -1.0
+	// The Levenshtein distance was calculated from the normalized search term, so is only known if that is measured
+	if search.isLevenshtein && search.measuredSearchTerm == search.query.term {
+		knownDistance = levenshteinDistance
+	}
+	wt.evaluateMatch(state, search, node, offsetsFromEnd, knownDistance)
+}
+
+// nextEditRow calculates the edit distances of each prefix of the given search runes from a key term extended by the
+// given rune, given the edit distances from the key term before it was extended.
+// This is a single column of the Levenshtein distance matrix.
+func nextEditRow(editRow []int, searchRunes []rune, keyRune rune) []int {
+	nextRow := make([]int, len(editRow))
+	nextRow[0] = editRow[0] + 1
+	for runeIndex := 1; runeIndex < len(editRow); runeIndex++ {
+		substitutionCost := 1
+		if searchRunes[runeIndex-1] == keyRune {
+			substitutionCost = 0
+		}
+		nextRow[runeIndex] = min(editRow[runeIndex]+1, nextRow[runeIndex-1]+1, editRow[runeIndex-1]+substitutionCost)
+	}
+	return nextRow
+}
+
+// minEditDistance gets the smallest of the given edit distances.
+func minEditDistance(editRow []int) int {
+	smallest := editRow[0]
+	for _, editDistance := range editRow[1:] {
+		smallest = min(smallest, editDistance)
+	}
+	return smallest
+}
@@ -0,0 +1,261 @@
+package trie
+
+import (
+	"github.com/coinbase/fuzzy-trie/pkg/levenshtein"
+	"github.com/coinbase/fuzzy-trie/pkg/similarity"
+	"math"
+	"unicode/utf8"
+)
+
+// Metric measures the distance between a normalized search term and the key term of a node in a tree, where a distance
+// of zero denotes identical terms and larger distances denote less similar terms.
+// Implementations must be safe for concurrent use.
+type Metric interface {
+	// Distance measures the distance between the given search term and key term.
+	Distance(searchTerm string, keyTerm string) float64
+}
+
+// LengthBoundedMetric is a Metric whose distances are never smaller than the difference in the number of runes of the
+// two terms being measured, as is the case for edit distances.
+// When a maximum distance is in effect, this allows key terms to be excluded without measuring them.
+type LengthBoundedMetric interface {
+	Metric
+	// IsLengthBounded determines if the metric's distances are bounded by the difference in the lengths of the terms.
+	IsLengthBounded() bool
+}
+
+// BoundedMetric is a Metric that can stop measuring the distance between two terms as soon as it is known to exceed a
+// maximum distance. When a maximum distance is in effect, key terms are measured with BoundedDistance instead of Distance.
+type BoundedMetric interface {
+	Metric
+	// BoundedDistance measures the distance between the given search term and key term if it is no more than the given
+	// maximum distance, returning false if it is not.
+	BoundedDistance(searchTerm string, keyTerm string, maxDistance int) (float64, bool)
+}
+
+// TranspositionMetric is a Metric that counts the transposition of two adjacent characters as fewer edits than the
+// substitutions it is made up of, as is the case for the Damerau-Levenshtein distance.
+// In MatchModeSubsequence, key terms then also match if they contain the characters of the search term in order once
+// any of its pairs of adjacent characters are transposed, so that, e.g., "tbc" matches "BTC".
+type TranspositionMetric interface {
+	Metric
+	// CountsTranspositions determines if the metric counts transpositions as fewer edits than substitutions.
+	CountsTranspositions() bool
+}
+
+// LevenshteinBoundedMetric is a Metric whose distances bound the Levenshtein distances between terms.
+// With MatchModeFuzzy, this allows the branches of a tree to be abandoned once none of their key terms can be within the
+// maximum distance; for metrics that do not implement it, every key term of a tree is measured.
+type LevenshteinBoundedMetric interface {
+	Metric
+	// MaxLevenshteinDistance gets the largest Levenshtein distance between two terms that are no more than the given
+	// maximum distance apart as measured by the metric, or a negative number if there is no such bound.
+	MaxLevenshteinDistance(maxDistance int) int
+}
+
+// SimilarityMetric is a Metric whose distances can be converted into similarities from 0 to 1, where 1 denotes identical
+// terms, which mean the same thing for short and long terms. The Similarity of each TreeMatch is given by the tree's
+// metric if it implements this interface; otherwise, it is 0.
+type SimilarityMetric interface {
+	Metric
+	// Similarity converts the given distance between the given search term and key term, as measured by the metric,
+	// into a similarity from 0 to 1.
+	Similarity(searchTerm string, keyTerm string, distance float64) float64
+}
+
+// DistanceFunc measures the distance between a normalized search term and the key term of a node in a tree.
+// It implements the Metric interface.
+type DistanceFunc func(searchTerm string, keyTerm string) int
+
+func (f DistanceFunc) Distance(searchTerm string, keyTerm string) float64 {
+	return float64(f(searchTerm, keyTerm))
+}
+
+// MetricFunc is a function implementation of the Metric interface.
+type MetricFunc func(searchTerm string, keyTerm string) float64
+
+func (f MetricFunc) Distance(searchTerm string, keyTerm string) float64 {
+	return f(searchTerm, keyTerm)
+}
+
+// damerauLevenshteinMetric is the Metric measuring Damerau-Levenshtein distances.
+type damerauLevenshteinMetric struct{}
+
+func (damerauLevenshteinMetric) Distance(searchTerm string, keyTerm string) float64 {
+	return float64(levenshtein.DamerauLevenshteinDistance(searchTerm, keyTerm))
+}
+
+func (damerauLevenshteinMetric) IsLengthBounded() bool {
+	return true
+}
+
+func (damerauLevenshteinMetric) CountsTranspositions() bool {
+	return true
+}
+
+// MaxLevenshteinDistance gets twice the given maximum distance, as each transposition is two Levenshtein edits.
+func (damerauLevenshteinMetric) MaxLevenshteinDistance(maxDistance int) int {
+	return 2 * maxDistance
+}
+
+func (damerauLevenshteinMetric) Similarity(searchTerm string, keyTerm string, distance float64) float64 {
+	return normalizeEditDistance(searchTerm, keyTerm, distance)
+}
+
+// editCostsMetric is a Metric measuring the total cost of the edits between terms.
+type editCostsMetric struct {
+	costs levenshtein.Costs
+}
+
+func (m editCostsMetric) Distance(searchTerm string, keyTerm string) float64 {
+	return m.costs.Distance(searchTerm, keyTerm)
+}
+
+// IsLengthBounded determines if the metric's distances are bounded by the difference in the lengths of the terms, which
+// is only the case if every insertion and deletion costs at least a whole edit.
+func (m editCostsMetric) IsLengthBounded() bool {
+	return m.costs.Insertion >= 1 && m.costs.Deletion >= 1
+}
+
+func (m editCostsMetric) Similarity(searchTerm string, keyTerm string, distance float64) float64 {
+	return normalizeEditDistance(searchTerm, keyTerm, distance)
+}
+
+func (m editCostsMetric) CountsTranspositions() bool {
+	return m.costs.Transposition > 0
+}
+
+// MaxLevenshteinDistance gets the number of the cheapest edits that fit within the given maximum distance, as no key
+// term can be more Levenshtein edits from the search term than that.
+func (m editCostsMetric) MaxLevenshteinDistance(maxDistance int) int {
+	minEditCost := m.costs.MinEditCost()
+	if minEditCost <= 0 {
+		return -1
+	}
+	// Allow for the rounding of the division, as a budget that is too large only abandons fewer branches
+	levenshteinBudget := math.Floor(float64(maxDistance)/minEditCost + 1e-9)
+	if levenshteinBudget > math.MaxInt32 {
+		return -1
+	}
+	return int(levenshteinBudget)
+}
+
+// levenshteinMetric is the Metric measuring Levenshtein distances, which a search can also calculate incrementally.
+type levenshteinMetric struct{}
+
+func (levenshteinMetric) Distance(searchTerm string, keyTerm string) float64 {
+	return float64(levenshtein.MyersDistance(searchTerm, keyTerm))
+}
+
+func (levenshteinMetric) IsLengthBounded() bool {
+	return true
+}
+
+func (levenshteinMetric) MaxLevenshteinDistance(maxDistance int) int {
+	return maxDistance
+}
+
+func (levenshteinMetric) Similarity(searchTerm string, keyTerm string, distance float64) float64 {
+	return normalizeEditDistance(searchTerm, keyTerm, distance)
+}
+
+func (levenshteinMetric) BoundedDistance(searchTerm string, keyTerm string, maxDistance int) (float64, bool) {
+	distance, isWithin := levenshtein.BoundedDistance(searchTerm, keyTerm, maxDistance)
+	return float64(distance), isWithin
+}
+
+// jaroWinklerMetric is the Metric measuring Jaro-Winkler distances.
+type jaroWinklerMetric struct{}
+
+func (jaroWinklerMetric) Distance(searchTerm string, keyTerm string) float64 {
+	return 1 - similarity.JaroWinkler(searchTerm, keyTerm)
+}
+
+// Similarity gets the Jaro-Winkler similarity of the terms, which the distance is one less.
+func (jaroWinklerMetric) Similarity(_ string, _ string, distance float64) float64 {
+	return 1 - distance
+}
+
+var (
+	// LevenshteinMetric measures the Levenshtein distance between terms; this is the default Metric of a DistanceTrees.
+	LevenshteinMetric Metric = levenshteinMetric{}
+
+	// DamerauLevenshteinMetric measures the Damerau-Levenshtein distance between terms, counting the transposition of two
+	// adjacent characters as a single edit.
+	DamerauLevenshteinMetric Metric = damerauLevenshteinMetric{}
+
+	// JaroWinklerMetric measures the Jaro-Winkler distance between terms: one less their Jaro-Winkler similarity (see
+	// similarity.JaroWinkler), from 0 for identical terms to 1 for terms with no characters in common. It favors key
+	// terms sharing the first few characters of the search term, which suits short terms such as names. As its
+	// distances are fractions of an edit, a maximum distance of 0 only matches identical terms and any greater
+	// maximum matches every term, and the Distances of each DistanceResult are truncated to 0.
+	JaroWinklerMetric Metric = jaroWinklerMetric{}
+)
+
+// NewEditCostsMetric builds a Metric measuring the total cost of the edits required to change the search term into a key
+// term with the given costs, such as levenshtein.TypoCosts. The distances it measures may be fractional, in which case
+// the Score of each DistanceResult reflects them in full while its Distances are truncated.
+// Key terms only match in MatchModeSubsequence if they contain the characters of the search term, so a search term with
+// substituted characters (e.g., "btv" for "BTC") is only matched in MatchModeFuzzy, where the branches of a tree are
+// abandoned once they are more of the costs' cheapest edits from the search term than fit within the maximum distance.
+func NewEditCostsMetric(costs levenshtein.Costs) Metric {
+	return editCostsMetric{costs: costs}
+}
+
+// isLevenshteinMetric determines if the given metric is known to measure Levenshtein distances.
+func isLevenshteinMetric(metric Metric) bool {
+	_, isLevenshtein := metric.(levenshteinMetric)
+	return isLevenshtein
+}
+
+// getBoundedMetric gets the given metric as a BoundedMetric, or nil if it cannot stop measuring at a maximum distance.
+func getBoundedMetric(metric Metric) BoundedMetric {
+	boundedMetric, _ := metric.(BoundedMetric)
+	return boundedMetric
+}
+
+// isLengthBounded determines if the given metric is known to be bounded by the difference in the lengths of the terms.
+func isLengthBounded(metric Metric) bool {
+	lengthBoundedMetric, isBounded := metric.(LengthBoundedMetric)
+	return isBounded && lengthBoundedMetric.IsLengthBounded()
+}
+
+// countsTranspositions determines if the given metric is known to count transpositions as fewer edits than substitutions.
+func countsTranspositions(metric Metric) bool {
+	transpositionMetric, isTransposition := metric.(TranspositionMetric)
+	return isTransposition && transpositionMetric.CountsTranspositions()
+}
+
+// getSimilarity gets the similarity of the given search term and key term at the given distance, as measured by the given
+// metric, or 0 if the metric does not give similarities.
+func getSimilarity(metric Metric, searchTerm string, keyTerm string, distance float64) float64 {
+	similarityMetric, isSimilarity := metric.(SimilarityMetric)
+	if !isSimilarity {
+		return 0
+	}
+	return similarityMetric.Similarity(searchTerm, keyTerm, distance)
+}
+
+// normalizeEditDistance gets the proportion of the runes of the longer of the given terms that the given edit distance
+// between them leaves unedited.
+func normalizeEditDistance(searchTerm string, keyTerm string, distance float64) float64 {
+	return similarity.NormalizeDistance(distance, utf8.RuneCountInString(searchTerm), utf8.RuneCountInString(keyTerm))
+}
+
+// getLevenshteinBudget gets the largest Levenshtein distance of the key terms within the given maximum distance of the
+// search term as measured by the given metric, or math.MaxInt if there is no maximum distance or the metric does not
+// bound the Levenshtein distance.
+func getLevenshteinBudget(metric Metric, maxDistance int) int {
+	if maxDistance < 0 {
+		return math.MaxInt
+	}
+
+	levenshteinBoundedMetric, isBounded := metric.(LevenshteinBoundedMetric)
+	if !isBounded {
+		return math.MaxInt
+	}
+	if levenshteinBudget := levenshteinBoundedMetric.MaxLevenshteinDistance(maxDistance); levenshteinBudget >= 0 {
+		return levenshteinBudget
+	}
+	return math.MaxInt
+}
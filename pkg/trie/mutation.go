@@ -0,0 +1,262 @@
+package trie
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrItemNotFound is returned when an item to be removed from a Tree is not present in the tree.
+var ErrItemNotFound = errors.New("item not found in tree")
+
+// Insert adds the given item to this tree, placing it according to the terms extracted by the tree's term extractor.
+// This is not safe to invoke concurrently with other operations on this tree.
+func (t *Tree[T]) Insert(ctx context.Context, item T) error {
+	update, err := t.prepareInsert(ctx, item)
+	if err != nil {
+		return err
+	}
+
+	update.apply()
+
+	return nil
+}
+
+// insertAt adds the given item to this tree under the given normalized key term, at the given token position.
+func (t *Tree[T]) insertAt(normalizedKeyTerm string, item T, tokenPosition int) {
+	currentNode := t.root
+	for _, keyRune := range []rune(normalizedKeyTerm) {
+		childNode, hasChild := currentNode.children[keyRune]
+		if !hasChild {
+			// The current node is gaining a child, so it can no longer be a leaf
+			t.removeLeaf(currentNode)
+
+			childRune := keyRune
+			childNode = newTrieNode[T](currentNode, &childRune)
+			currentNode.children[keyRune] = childNode
+			t.addLeaf(childNode)
+		}
+		currentNode = childNode
+	}
+
+	currentNode.keyTerm = normalizedKeyTerm
+	currentNode.addValue(item, tokenPosition)
+}
+
+// Remove removes all occurrences of the given item from this tree, pruning any branches of the tree left without values.
+// If the item is not in the tree under any of its terms, this returns an error wrapping ErrItemNotFound.
+// This is not safe to invoke concurrently with other operations on this tree.
+func (t *Tree[T]) Remove(ctx context.Context, item T) error {
+	update, err := t.prepareRemove(ctx, item)
+	if err != nil {
+		return err
+	}
+
+	update.apply()
+
+	return nil
+}
+
+// removeAt removes all occurrences of the given item from this tree under the given normalized key term, returning
+// whether any were found.
+func (t *Tree[T]) removeAt(normalizedKeyTerm string, item T) bool {
+	currentNode := t.root
+	for _, keyRune := range []rune(normalizedKeyTerm) {
+		childNode, hasChild := currentNode.children[keyRune]
+		if !hasChild {
+			return false
+		}
+		currentNode = childNode
+	}
+
+	remainingValues := currentNode.values[:0]
+	remainingTokenPositions := currentNode.tokenPositions[:0]
+	for valueIndex, value := range currentNode.values {
+		if !valuesEqual(value, item) {
+			remainingValues = append(remainingValues, value)
+			if currentNode.tokenPositions != nil {
+				remainingTokenPositions = append(remainingTokenPositions, currentNode.tokenPositions[valueIndex])
+			}
+		}
+	}
+
+	if len(remainingValues) == len(currentNode.values) {
+		return false
+	}
+
+	// Clear out the trailing references so the removed items can be garbage-collected
+	clear(currentNode.values[len(remainingValues):])
+	currentNode.values = remainingValues
+	currentNode.tokenPositions = remainingTokenPositions
+	if len(currentNode.values) == 0 {
+		currentNode.values = nil
+		currentNode.tokenPositions = nil
+	}
+
+	t.prune(currentNode)
+
+	return true
+}
+
+// Update replaces the given old item with the given new item, re-placing it in the tree according to the new item's term.
+// If the old item is not in the tree, this returns an error wrapping ErrItemNotFound and the new item is not inserted.
+// The terms of both items are extracted before the tree is changed, so the tree is left unchanged if either fails.
+// This is not safe to invoke concurrently with other operations on this tree.
+func (t *Tree[T]) Update(ctx context.Context, oldItem T, newItem T) error {
+	update, err := t.prepareUpdate(ctx, oldItem, newItem)
+	if err != nil {
+		return err
+	}
+
+	update.apply()
+
+	return nil
+}
+
+// treeUpdate is a change to a Tree, removing an old item and inserting a new item in its place, either of which may be
+// absent, whose key terms have already been extracted so that it can be applied without failing.
+type treeUpdate[T any] struct {
+	tree          *Tree[T]
+	oldItem       T
+	newItem       T
+	oldPlacements []keyTermPlacement
+	newPlacements []keyTermPlacement
+}
+
+// prepareInsert extracts the key terms of the given item to be inserted, without changing this tree.
+func (t *Tree[T]) prepareInsert(ctx context.Context, item T) (*treeUpdate[T], error) {
+	placements, err := extractKeyTerms(ctx, item, t.termExtractor, t.normalizer, t.tokenizer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &treeUpdate[T]{tree: t, newItem: item, newPlacements: placements}, nil
+}
+
+// prepareRemove extracts the key terms of the given item to be removed, without changing this tree, returning an error
+// wrapping ErrItemNotFound if the item is not in the tree under any of them.
+func (t *Tree[T]) prepareRemove(ctx context.Context, item T) (*treeUpdate[T], error) {
+	placements, err := extractKeyTerms(ctx, item, t.termExtractor, t.normalizer, t.tokenizer)
+	if err != nil {
+		return nil, err
+	}
+
+	isFound := false
+	itemKeyTerms := make([]string, len(placements))
+	for placementIndex, placement := range placements {
+		itemKeyTerms[placementIndex] = placement.keyTerm
+		if t.containsAt(placement.keyTerm, item) {
+			isFound = true
+		}
+	}
+	if !isFound {
+		return nil, fmt.Errorf("item is not stored at term(s) %q: %w", itemKeyTerms, ErrItemNotFound)
+	}
+
+	return &treeUpdate[T]{tree: t, oldItem: item, oldPlacements: placements}, nil
+}
+
+// prepareUpdate extracts the key terms of the given old and new items, without changing this tree, returning an error
+// if either cannot be extracted or the old item is not in the tree.
+func (t *Tree[T]) prepareUpdate(ctx context.Context, oldItem T, newItem T) (*treeUpdate[T], error) {
+	removal, err := t.prepareRemove(ctx, oldItem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove old item: %w", err)
+	}
+
+	insertion, err := t.prepareInsert(ctx, newItem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert new item: %w", err)
+	}
+
+	removal.newItem = newItem
+	removal.newPlacements = insertion.newPlacements
+	return removal, nil
+}
+
+// apply removes the old item from the tree, if there is one, and inserts the new item in its place, if there is one.
+func (u *treeUpdate[T]) apply() {
+	for _, placement := range u.oldPlacements {
+		u.tree.removeAt(placement.keyTerm, u.oldItem)
+	}
+	for _, placement := range u.newPlacements {
+		u.tree.insertAt(placement.keyTerm, u.newItem, placement.tokenPosition)
+	}
+}
+
+// containsAt determines if the given item is stored in this tree under the given normalized key term.
+func (t *Tree[T]) containsAt(normalizedKeyTerm string, item T) bool {
+	currentNode := t.root
+	for _, keyRune := range []rune(normalizedKeyTerm) {
+		if currentNode = currentNode.children[keyRune]; currentNode == nil {
+			return false
+		}
+	}
+
+	for _, value := range currentNode.values {
+		if valuesEqual(value, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// prune removes the given node and its ancestors from the tree for as long as they hold neither values nor children,
+// promoting the first surviving ancestor to a leaf node if it is left without children.
+func (t *Tree[T]) prune(node *Node[T]) {
+	currentNode := node
+	for {
+		if len(currentNode.children) > 0 || len(currentNode.values) > 0 || currentNode.parent == nil {
+			break
+		}
+
+		parentNode := currentNode.parent
+		delete(parentNode.children, *currentNode.keyRune)
+		t.removeLeaf(currentNode)
+		currentNode.parent = nil
+
+		currentNode = parentNode
+	}
+
+	if len(currentNode.children) == 0 {
+		t.addLeaf(currentNode)
+	}
+}
+
+// addLeaf adds the given node to this tree's leaf nodes, if it is not already one.
+func (t *Tree[T]) addLeaf(node *Node[T]) {
+	if node.leafIndex >= 0 {
+		return
+	}
+
+	node.leafIndex = len(t.leafNodes)
+	t.leafNodes = append(t.leafNodes, node)
+}
+
+// removeLeaf removes the given node from this tree's leaf nodes, if it is one.
+func (t *Tree[T]) removeLeaf(node *Node[T]) {
+	if node.leafIndex < 0 {
+		return
+	}
+
+	// Swap the last leaf node into the removed node's place
+	lastIndex := len(t.leafNodes) - 1
+	lastNode := t.leafNodes[lastIndex]
+	t.leafNodes[node.leafIndex] = lastNode
+	lastNode.leafIndex = node.leafIndex
+	t.leafNodes[lastIndex] = nil
+	t.leafNodes = t.leafNodes[:lastIndex]
+
+	node.leafIndex = -1
+}
+
+// valuesEqual determines if the two given values are equal, falling back to a deep comparison for values whose
+// types cannot be compared with the == operator.
+func valuesEqual[T any](a, b T) bool {
+	aValue, bValue := any(a), any(b)
+	if aType := reflect.TypeOf(aValue); aType != nil && !aType.Comparable() {
+		return reflect.DeepEqual(aValue, bValue)
+	}
+	return aValue == bValue
+}
@@ -0,0 +1,95 @@
+package trie
+
+import (
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"golang.org/x/text/width"
+	"strings"
+	"unicode"
+)
+
+// Normalizer normalizes terms to a consistent format.
+// A tree's Normalizer is applied both to the key terms of the items placed into the tree and to the terms it is searched
+// for, so any two terms that normalize to the same value are considered identical.
+// Implementations must be safe for concurrent use.
+type Normalizer interface {
+	// Normalize normalizes the given term.
+	Normalize(term string) string
+}
+
+// NormalizerFunc is a function implementation of the Normalizer interface.
+type NormalizerFunc func(term string) string
+
+func (f NormalizerFunc) Normalize(term string) string {
+	return f(term)
+}
+
+var (
+	// UpperCaseNormalizer upper-cases terms; this is the default Normalizer of a Tree.
+	UpperCaseNormalizer Normalizer = NormalizerFunc(strings.ToUpper)
+
+	// CaseFoldingNormalizer applies Unicode case folding to terms, which, unlike upper-casing, treats characters such
+	// as 'ß' and "ss" as equivalent.
+	CaseFoldingNormalizer Normalizer = NormalizerFunc(foldCase)
+
+	// DiacriticStrippingNormalizer removes diacritical marks from terms (e.g., "Café" becomes "Cafe").
+	DiacriticStrippingNormalizer Normalizer = NormalizerFunc(stripDiacritics)
+
+	// SeparatorCollapsingNormalizer replaces each run of whitespace and punctuation within terms with a single space,
+	// trimming any from the start and end of the terms.
+	SeparatorCollapsingNormalizer Normalizer = NormalizerFunc(collapseSeparators)
+
+	// WidthFoldingNormalizer maps the full-width and half-width forms of characters to their canonical forms
+	// (e.g., "ＢＴＣ" becomes "BTC").
+	WidthFoldingNormalizer Normalizer = NormalizerFunc(width.Fold.String)
+
+	// InternationalNormalizer combines width folding, diacritic stripping, case folding and separator collapsing, such
+	// that, e.g., "Café  Crème" and "cafe-creme" are equivalent.
+	InternationalNormalizer = ChainNormalizers(
+		WidthFoldingNormalizer,
+		DiacriticStrippingNormalizer,
+		CaseFoldingNormalizer,
+		SeparatorCollapsingNormalizer,
+	)
+)
+
+// ChainNormalizers builds a Normalizer that applies each of the given normalizers in order.
+func ChainNormalizers(normalizers ...Normalizer) Normalizer {
+	return NormalizerFunc(func(term string) string {
+		for _, normalizer := range normalizers {
+			term = normalizer.Normalize(term)
+		}
+		return term
+	})
+}
+
+// foldCase applies Unicode case folding to the given term.
+func foldCase(term string) string {
+	// Casers hold state, so a new one is needed for each use
+	return cases.Fold().String(term)
+}
+
+// stripDiacritics removes the diacritical marks from the given term by decomposing its characters and dropping the
+// resulting non-spacing marks.
+func stripDiacritics(term string) string {
+	// Transformer chains hold state, so a new one is needed for each use
+	stripper := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	stripped, _, err := transform.String(stripper, term)
+	if err != nil {
+		// This can only happen for invalid UTF-8; leave such terms untouched
+		return term
+	}
+	return stripped
+}
+
+// collapseSeparators replaces each run of whitespace and punctuation within the given term with a single space.
+func collapseSeparators(term string) string {
+	return strings.Join(strings.FieldsFunc(term, isSeparator), " ")
+}
+
+// isSeparator determines if the given rune separates words within a term.
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r)
+}
@@ -0,0 +1,51 @@
+package trie_test
+
+import (
+	"context"
+	"github.com/coinbase/fuzzy-trie/pkg/trie"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Normalizer", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = newTestContext()
+	})
+
+	DescribeTable("built-in normalizers",
+		func(normalizer trie.Normalizer, term string, expected string) {
+			Expect(normalizer.Normalize(term)).To(Equal(expected), "the term should be normalized")
+		},
+		Entry("upper-casing", trie.UpperCaseNormalizer, "Café", "CAFÉ"),
+		Entry("case folding", trie.CaseFoldingNormalizer, "Straße", "strasse"),
+		Entry("diacritic stripping", trie.DiacriticStrippingNormalizer, "Crème Brûlée", "Creme Brulee"),
+		Entry("diacritic stripping of non-Latin scripts", trie.DiacriticStrippingNormalizer, "Ελλάδα", "Ελλαδα"),
+		Entry("separator collapsing", trie.SeparatorCollapsingNormalizer, "  Wild--cat,\t domestic ", "Wild cat domestic"),
+		Entry("width folding", trie.WidthFoldingNormalizer, "ＢＴＣ ｶﾀｶﾅ", "BTC カタカナ"),
+		Entry("international", trie.InternationalNormalizer, "  Café—CRÈME ", "cafe creme"),
+		Entry("chained", trie.ChainNormalizers(trie.DiacriticStrippingNormalizer, trie.UpperCaseNormalizer), "Café", "CAFE"),
+	)
+
+	It("should be applied to both the key terms and the search terms", func() {
+		cafe := newTestComparableFuzzable("Café")
+		cafeteria := newTestComparableFuzzable("CAFETERIA")
+		tree := loadTestTree(ctx, []*testComparableFuzzable{cafe, cafeteria}, trie.WithNormalizer(trie.InternationalNormalizer))
+
+		distanceTrees := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{tree})
+		results, err := distanceTrees.SearchWithOptions(ctx, "cafe", trie.SearchOptions{})
+		Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+		Expect(results).To(HaveLen(2), "both items should be found")
+		Expect(results[0].Result).To(Equal(cafe), "the accented item should be an exact match")
+		Expect(results[0].Matches[0].Distance).To(BeZero(), "the accented item should be an exact match")
+
+		cafeAuLait := newTestComparableFuzzable("Café au lait")
+		Expect(tree.Insert(ctx, cafeAuLait)).To(Succeed(), "inserting should not fail")
+		results, err = distanceTrees.SearchWithOptions(ctx, "ＣＡＦＥ　ＡＵ　ＬＡＩＴ", trie.SearchOptions{})
+		Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+		Expect(results).To(HaveLen(1), "the inserted item should be found")
+		Expect(results[0].Result).To(Equal(cafeAuLait), "the inserted item should be found")
+		Expect(results[0].Matches[0].Distance).To(BeZero(), "the inserted item should be an exact match")
+	})
+})
@@ -0,0 +1,38 @@
+package trie
+
+import "strings"
+
+// PreparedQuery is a search term prepared for matching against the nodes of a particular tree.
+// The search term is normalized once, in the same manner as the tree's key terms, so that it can be matched against
+// any number of the tree's nodes without being normalized again.
+type PreparedQuery struct {
+	// term is the search term, normalized by the tree's Normalizer
+	term  string
+	runes []rune
+	// tokenCount is the number of tokens in the term if the tree was loaded with a Tokenizer, or 0 otherwise
+	tokenCount int
+}
+
+// PrepareQuery prepares the given search term for matching against the nodes of this tree.
+func (t *Tree[T]) PrepareQuery(searchTerm string) *PreparedQuery {
+	normalizedTerm := t.Normalize(searchTerm)
+	query := &PreparedQuery{
+		term:  normalizedTerm,
+		runes: []rune(normalizedTerm),
+	}
+	if t.tokenizer != nil {
+		query.tokenCount = strings.Count(normalizedTerm, tokenSeparator) + 1
+	}
+	return query
+}
+
+// Term gets the normalized search term of this query.
+func (q *PreparedQuery) Term() string {
+	return q.term
+}
+
+// ContainsQuery determines if the key term of this node contains the characters of the given query, prepared by the
+// tree of this node, in the order given. See Contains for more details.
+func (n *Node[T]) ContainsQuery(query *PreparedQuery) bool {
+	return n.containsRunes(query.runes)
+}
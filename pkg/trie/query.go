@@ -0,0 +1,138 @@
+package trie
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+)
+
+// QueryOperator determines whether a search term is split into multiple query terms that are matched independently,
+// and how the matches of those terms are combined.
+type QueryOperator int
+
+const (
+	// QueryOperatorNone matches the search term as a whole, as a single query term.
+	QueryOperatorNone QueryOperator = iota
+	// QueryOperatorAnd splits the search term into query terms, only returning results that match every query term in
+	// at least one of the trees.
+	QueryOperatorAnd
+	// QueryOperatorOr splits the search term into query terms, returning results that match any of the query terms in
+	// any of the trees.
+	QueryOperatorOr
+)
+
+// parseQuery splits the given search term into the query terms to be matched independently, according to the given
+// options. The search term is matched as a whole if the options do not split it or it holds no tokens.
+func parseQuery(searchTerm string, options SearchOptions) []string {
+	if options.QueryOperator == QueryOperatorNone {
+		return []string{searchTerm}
+	}
+
+	tokenizer := options.QueryTokenizer
+	if tokenizer == nil {
+		tokenizer = WordTokenizer
+	}
+
+	queryTerms := tokenizer.Tokenize(searchTerm)
+	if len(queryTerms) == 0 {
+		return []string{searchTerm}
+	}
+	return queryTerms
+}
+
+// searchQueryTerms searches the trees for each of the given query terms independently, combining the matches of each
+// result according to the options' query operator.
+func (wt *DistanceTrees[T]) searchQueryTerms(ctx context.Context, queryTerms []string, options SearchOptions) (map[T]*DistanceResult[T], error) {
+	results := make(map[T]*DistanceResult[T])
+	// unmatchedDistances holds the distance added for each query term in each tree in which a result did not match it
+	unmatchedDistances := make([][]float64, len(queryTerms))
+	for termIndex, queryTerm := range queryTerms {
+		state := &searchState[T]{
+			searchTerm: queryTerm,
+			options:    options,
+			results:    make(map[T]*DistanceResult[T]),
+		}
+
+		unmatchedDistances[termIndex] = make([]float64, len(wt.trees))
+		for treeIndex, weightedTree := range wt.trees {
+			if searchErr := wt.searchTree(ctx, treeIndex, state); searchErr != nil {
+				return nil, fmt.Errorf("failed to search tree at index %d for query term %q: %w", treeIndex, queryTerm, searchErr)
+			}
+			// A missing query term costs as much as inserting all its characters would
+			unmatchedDistances[termIndex][treeIndex] = float64(utf8.RuneCountInString(weightedTree.Tree.Normalize(queryTerm)))
+		}
+
+		for item, termResult := range state.results {
+			result, hasResult := results[item]
+			if !hasResult {
+				result = wt.newDistanceResult(item)
+				result.TermMatches = make([][]*TreeMatch, len(queryTerms))
+				result.termValueIndices = make([][]int, len(queryTerms))
+				result.rawTreeDistances = make([]float64, len(wt.trees))
+				results[item] = result
+			}
+			result.TermMatches[termIndex] = termResult.Matches
+			result.termValueIndices[termIndex] = termResult.valueIndices
+		}
+	}
+
+	for item, result := range results {
+		if !wt.combineTermMatches(result, unmatchedDistances, options.QueryOperator) {
+			delete(results, item)
+		}
+	}
+
+	return results, nil
+}
+
+// combineTermMatches combines the matches of each query term of the given result into its distances and matches in each
+// tree, returning false if the result does not satisfy the given query operator.
+// The distance of the result in a tree is the sum of the distances of each query term it matched in that tree, plus
+// the given unmatched distance of each query term it did not.
+func (wt *DistanceTrees[T]) combineTermMatches(result *DistanceResult[T], unmatchedDistances [][]float64, operator QueryOperator) bool {
+	if operator == QueryOperatorAnd {
+		for _, termMatches := range result.TermMatches {
+			if termMatches == nil {
+				return false
+			}
+		}
+	}
+
+	primaryDistanceFactor := 1.0
+	if distanceFactor := result.Result.GetPrimaryDistanceFactor(); distanceFactor != nil {
+		primaryDistanceFactor = *distanceFactor
+	}
+
+	for treeIndex := range wt.trees {
+		isMatched := false
+		rawDistance := 0.0
+		for termIndex, termMatches := range result.TermMatches {
+			if termMatches == nil || termMatches[treeIndex] == nil {
+				rawDistance += unmatchedDistances[termIndex][treeIndex]
+				continue
+			}
+
+			termMatch := termMatches[treeIndex]
+			rawDistance += termMatch.Distance
+			isMatched = true
+			// The closest of the query terms' matches represents the result's match in the tree
+			if treeMatch := result.Matches[treeIndex]; treeMatch == nil ||
+				isCloserMatch(termMatch.Distance, termMatch.KeyTerm, treeMatch.Distance, treeMatch.KeyTerm) {
+				result.Matches[treeIndex] = termMatch
+				result.valueIndices[treeIndex] = result.termValueIndices[termIndex][treeIndex]
+			}
+		}
+
+		if !isMatched {
+			continue
+		}
+
+		scaledDistance := rawDistance * primaryDistanceFactor
+		roundedDistance := int(scaledDistance) + wt.trees[treeIndex].distanceOffset
+		result.Distances[treeIndex] = &roundedDistance
+		result.treeDistances[treeIndex] = scaledDistance
+		result.rawTreeDistances[treeIndex] = rawDistance
+	}
+
+	return true
+}
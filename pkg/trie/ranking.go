@@ -0,0 +1,312 @@
+package trie
+
+import (
+	"cmp"
+	"container/heap"
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GroupOrdering determines how results belonging to different sorting groups are ordered relative to each other.
+type GroupOrdering int
+
+const (
+	// GroupOrderingGrouped ranks all results of a sorting group ahead of the results of any higher-indexed sorting group,
+	// ordering the results within each sorting group by their distances.
+	GroupOrderingGrouped GroupOrdering = iota
+	// GroupOrderingInterleaved ranks the results by taking the next-best result from each sorting group in turn, in
+	// ascending order of the sorting groups, until every group is exhausted.
+	GroupOrderingInterleaved
+	// GroupOrderingIgnored ranks the results purely by their distances, disregarding their sorting groups.
+	GroupOrderingIgnored
+)
+
+// resultComparator compares two DistanceResult objects, returning a negative number if the first is ranked better than
+// the second, a positive number if it is ranked worse, and zero otherwise.
+type resultComparator[T any] func(result *DistanceResult[T], otherResult *DistanceResult[T]) int
+
+// rankResults orders the given DistanceResult objects according to their sorting groups and comparative distances,
+// returning the page of them described by the given options.
+// If a limit is given, only the best results required to fill the page are kept and sorted, rather than the full result set.
+func (wt *DistanceTrees[T]) rankResults(ctx context.Context, weightedResults []*DistanceResult[T], options SearchOptions) []*DistanceResult[T] {
+	sortStart := time.Now()
+	defer func() {
+		_ = wt.timer.RecordSortTime(ctx, time.Since(sortStart))
+	}()
+
+	// pageEnd is the number of best-ranked results needed to fill the requested page
+	pageEnd := len(weightedResults)
+	if options.Limit > 0 {
+		pageEnd = min(pageEnd, options.Offset+options.Limit)
+	}
+
+	comparator := wt.getResultComparator()
+	if options.GroupOrdering == GroupOrderingInterleaved || options.MaxResultsPerGroup > 0 {
+		weightedResults = rankGroupedResults(weightedResults, options, pageEnd, comparator)
+	} else if options.GroupOrdering == GroupOrderingIgnored {
+		weightedResults = selectTopResults(weightedResults, pageEnd, comparator)
+	} else {
+		weightedResults = selectTopResults(weightedResults, pageEnd, groupResultComparator(comparator))
+	}
+
+	if options.Explain {
+		// The first result of the page is explained relative to the last result of the previous page
+		explainRanking(weightedResults[:min(len(weightedResults), pageEnd)], options.Offset, options, wt.rankByScore)
+	}
+
+	if options.Offset >= len(weightedResults) {
+		return nil
+	}
+
+	return weightedResults[options.Offset:min(len(weightedResults), pageEnd)]
+}
+
+// getResultComparator gets the comparator ranking results within a sorting group.
+func (wt *DistanceTrees[T]) getResultComparator() resultComparator[T] {
+	if wt.rankByScore {
+		return compareScoredResults[T]
+	}
+	return compareResults[T]
+}
+
+// rankGroupedResults ranks the given DistanceResult objects by first ranking each sorting group individually, capping
+// each group at the options' maximum results per group, and then combining the groups according to the options' group
+// ordering. Results within a sorting group are ordered by the given comparator, and at most the given number of results
+// is returned.
+func rankGroupedResults[T any](
+	weightedResults []*DistanceResult[T],
+	options SearchOptions,
+	count int,
+	comparator resultComparator[T],
+) []*DistanceResult[T] {
+	resultsByGroup := make(map[int][]*DistanceResult[T])
+	for _, weightedResult := range weightedResults {
+		resultsByGroup[weightedResult.sortingGroup] = append(resultsByGroup[weightedResult.sortingGroup], weightedResult)
+	}
+
+	groupCount := count
+	if options.MaxResultsPerGroup > 0 {
+		groupCount = min(groupCount, options.MaxResultsPerGroup)
+	}
+
+	sortingGroups := make([]int, 0, len(resultsByGroup))
+	for sortingGroup, groupResults := range resultsByGroup {
+		sortingGroups = append(sortingGroups, sortingGroup)
+		resultsByGroup[sortingGroup] = selectTopResults(groupResults, groupCount, comparator)
+	}
+	sort.Ints(sortingGroups)
+
+	rankedResults := make([]*DistanceResult[T], 0, count)
+	switch options.GroupOrdering {
+	case GroupOrderingInterleaved:
+		for rank := 0; len(rankedResults) < count; rank++ {
+			appended := false
+			for _, sortingGroup := range sortingGroups {
+				if groupResults := resultsByGroup[sortingGroup]; rank < len(groupResults) && len(rankedResults) < count {
+					rankedResults = append(rankedResults, groupResults[rank])
+					appended = true
+				}
+			}
+
+			if !appended {
+				break
+			}
+		}
+	case GroupOrderingIgnored:
+		for _, sortingGroup := range sortingGroups {
+			rankedResults = append(rankedResults, resultsByGroup[sortingGroup]...)
+		}
+		rankedResults = selectTopResults(rankedResults, count, comparator)
+	default:
+		for _, sortingGroup := range sortingGroups {
+			rankedResults = append(rankedResults, resultsByGroup[sortingGroup]...)
+		}
+	}
+
+	return rankedResults[:min(len(rankedResults), count)]
+}
+
+// sortResults sorts the given DistanceResult objects using the given comparator.
+func sortResults[T any](weightedResults []*DistanceResult[T], comparator resultComparator[T]) {
+	sort.Slice(weightedResults, func(i, j int) bool {
+		return comparator(weightedResults[i], weightedResults[j]) < 0
+	})
+}
+
+// selectTopResults selects the given number of best-ranked DistanceResult objects, returning them in sorted order.
+// This uses a heap bounded to the given count so that only the selected results are ever sorted.
+func selectTopResults[T any](weightedResults []*DistanceResult[T], count int, comparator resultComparator[T]) []*DistanceResult[T] {
+	if len(weightedResults) <= count {
+		sortResults(weightedResults, comparator)
+		return weightedResults
+	}
+
+	topResults := &resultHeap[T]{
+		comparator: comparator,
+	}
+	for _, weightedResult := range weightedResults {
+		if topResults.Len() < count {
+			heap.Push(topResults, weightedResult)
+		} else if count > 0 && comparator(weightedResult, topResults.results[0]) < 0 {
+			// This result is better than the worst of the best results so far, so it replaces it
+			topResults.replaceWorst(weightedResult)
+		}
+	}
+
+	// Popping the worst result off each time fills the slice from the back
+	selectedResults := make([]*DistanceResult[T], topResults.Len())
+	for resultIndex := len(selectedResults) - 1; resultIndex >= 0; resultIndex-- {
+		selectedResults[resultIndex] = heap.Pop(topResults).(*DistanceResult[T])
+	}
+
+	return selectedResults
+}
+
+// groupResultComparator builds a comparator that compares DistanceResult objects first by their sorting groups and then
+// by the given comparator.
+func groupResultComparator[T any](comparator resultComparator[T]) resultComparator[T] {
+	return func(result *DistanceResult[T], otherResult *DistanceResult[T]) int {
+		if comparison := compareSortingGroups(result, otherResult); comparison != 0 {
+			return comparison
+		}
+
+		return comparator(result, otherResult)
+	}
+}
+
+// compareScoredResults compares the two given DistanceResult objects first by their scores, ranking higher scores
+// first, and then by their distances.
+func compareScoredResults[T any](result *DistanceResult[T], otherResult *DistanceResult[T]) int {
+	if comparison := compareScores(result, otherResult); comparison != 0 {
+		return comparison
+	}
+
+	return compareResults(result, otherResult)
+}
+
+// compareResults compares the two given DistanceResult objects according to their distances, breaking ties between
+// equally-distant results with compareMatchOrder.
+func compareResults[T any](result *DistanceResult[T], otherResult *DistanceResult[T]) int {
+	if comparison, _ := compareDistances(result, otherResult); comparison != 0 {
+		return comparison
+	}
+
+	return compareMatchOrder(result, otherResult)
+}
+
+// compareMatchOrder compares two DistanceResult objects with equal distances by their matches in each tree in turn,
+// so that they are ranked in the same order however the results were found, and pages of results neither repeat nor
+// skip any of them. Their matches are compared by their unrounded distances, then by their key terms and then by the
+// order in which the results were placed under those key terms.
+func compareMatchOrder[T any](result *DistanceResult[T], otherResult *DistanceResult[T]) int {
+	for treeIndex, treeMatch := range result.Matches {
+		otherTreeMatch := otherResult.Matches[treeIndex]
+		if treeMatch == nil || otherTreeMatch == nil {
+			// Results with equal distances are matched in the same trees
+			continue
+		}
+
+		if comparison := cmp.Compare(result.treeDistances[treeIndex], otherResult.treeDistances[treeIndex]); comparison != 0 {
+			return comparison
+		}
+		if comparison := strings.Compare(treeMatch.KeyTerm, otherTreeMatch.KeyTerm); comparison != 0 {
+			return comparison
+		}
+		if comparison := cmp.Compare(result.valueIndices[treeIndex], otherResult.valueIndices[treeIndex]); comparison != 0 {
+			return comparison
+		}
+	}
+
+	return 0
+}
+
+// compareSortingGroups compares the two given DistanceResult objects by their sorting groups, ranking lower sorting
+// groups first.
+func compareSortingGroups[T any](result *DistanceResult[T], otherResult *DistanceResult[T]) int {
+	if result.sortingGroup < otherResult.sortingGroup {
+		return -1
+	} else if result.sortingGroup > otherResult.sortingGroup {
+		return 1
+	}
+
+	return 0
+}
+
+// compareScores compares the two given DistanceResult objects by their scores, ranking higher scores first.
+func compareScores[T any](result *DistanceResult[T], otherResult *DistanceResult[T]) int {
+	if result.Score > otherResult.Score {
+		return -1
+	} else if result.Score < otherResult.Score {
+		return 1
+	}
+
+	return 0
+}
+
+// compareDistances compares the two given DistanceResult objects according to their distances, also returning the
+// index of the distance that decided the comparison, or -1 if the distances are all equal.
+func compareDistances[T any](result *DistanceResult[T], otherResult *DistanceResult[T]) (int, int) {
+	for distanceIndex := 0; distanceIndex < len(result.Distances); distanceIndex++ {
+		distance := result.Distances[distanceIndex]
+		otherDistance := otherResult.Distances[distanceIndex]
+		// If the result's distance here is nil and the other's is non-nil, then this should actually be ranked as _greater_
+		// because that means that the result was not a match for the search term. Conversely, if the other is nil
+		// and the result is non-nil, then it should be considered 'less than' for purposes of sorting, as that means
+		// the result matched the search term.
+		if distance == nil {
+			if otherDistance != nil {
+				return 1, distanceIndex
+			}
+		} else if otherDistance == nil {
+			return -1, distanceIndex
+		} else if *distance < *otherDistance {
+			return -1, distanceIndex
+		} else if *distance > *otherDistance {
+			return 1, distanceIndex
+		}
+	}
+
+	return 0, -1
+}
+
+// resultHeap is a heap of DistanceResult objects that keeps the worst-ranked result at its root.
+type resultHeap[T any] struct {
+	results    []*DistanceResult[T]
+	comparator resultComparator[T]
+}
+
+func (h *resultHeap[T]) Len() int {
+	return len(h.results)
+}
+
+func (h *resultHeap[T]) Less(i, j int) bool {
+	return h.comparator(h.results[i], h.results[j]) > 0
+}
+
+func (h *resultHeap[T]) Swap(i, j int) {
+	h.results[i], h.results[j] = h.results[j], h.results[i]
+	h.results[i].heapIndex = i
+	h.results[j].heapIndex = j
+}
+
+func (h *resultHeap[T]) Push(x any) {
+	result := x.(*DistanceResult[T])
+	result.heapIndex = len(h.results)
+	h.results = append(h.results, result)
+}
+
+func (h *resultHeap[T]) Pop() any {
+	last := h.results[len(h.results)-1]
+	h.results[len(h.results)-1] = nil
+	h.results = h.results[:len(h.results)-1]
+	return last
+}
+
+// replaceWorst replaces the worst-ranked result in this heap with the given result.
+func (h *resultHeap[T]) replaceWorst(result *DistanceResult[T]) {
+	result.heapIndex = 0
+	h.results[0] = result
+	heap.Fix(h, 0)
+}
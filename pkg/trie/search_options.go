@@ -0,0 +1,109 @@
+package trie
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSearchOptions is returned when a search is given SearchOptions that cannot be applied.
+var ErrInvalidSearchOptions = errors.New("invalid search options")
+
+// SearchOptions configures the behavior of a search of a DistanceTrees instance.
+// The zero value imposes no restrictions on the search.
+type SearchOptions struct {
+	// MaxDistance, if set, is the largest Levenshtein distance a tree's key term may be from the search term for
+	// the values under that key term to be considered a match in that tree; it must not be negative.
+	MaxDistance *int
+	// TypoBudget, if set, determines the largest distance allowed based on the length of the search term.
+	// If both this and MaxDistance are set, the smaller of the two limits is applied. The search fails if it returns a
+	// negative distance.
+	TypoBudget TypoBudget
+	// Limit, if greater than zero, is the maximum number of results to be returned; it must not be negative.
+	// Only the best results needed to fill the requested page are ranked, which avoids sorting the full result set.
+	// When searching a single tree, without MaxResultsPerGroup or GroupOrderingInterleaved, only those results are kept
+	// while the tree is being searched.
+	Limit int
+	// Offset is the number of best-ranked results to be skipped, allowing pages of results to be requested; it must not
+	// be negative. Results are always ranked in the same order, so consecutive pages neither repeat nor skip results.
+	Offset int
+	// GroupOrdering determines how results in different sorting groups are ordered relative to each other.
+	// By default, results are ranked by sorting group and then by distance.
+	GroupOrdering GroupOrdering
+	// MaxResultsPerGroup, if greater than zero, is the maximum number of results to be returned from each sorting group.
+	MaxResultsPerGroup int
+	// MatchMode determines how the key terms of the trees must contain the search term to match it.
+	// By default, key terms match if they contain the characters of the search term in order, even if not contiguously.
+	MatchMode MatchMode
+	// PrefixEditBudget is the largest number of edits allowed between the search term and a prefix of a key term for
+	// the key term to match with MatchModeFuzzyPrefix; it must not be negative.
+	PrefixEditBudget int
+	// QueryOperator determines whether the search term is split into query terms that are matched independently, and
+	// how their matches are combined. By default, the search term is matched as a whole.
+	// A result's distance in a tree is the sum of the distances of the query terms it matched in that tree, with each
+	// query term it did not match there counting as the number of runes in that query term.
+	QueryOperator QueryOperator
+	// QueryTokenizer splits the search term into query terms if a QueryOperator is set; if not set, WordTokenizer is used.
+	QueryTokenizer Tokenizer
+	// Explain, if set, populates the Explanation of each result with a breakdown of how it was scored and ranked.
+	Explain bool
+	// measuresSearchTermAsGiven is set by Search to measure distances from the search term as given, rather than as
+	// normalized by each tree's Normalizer
+	measuresSearchTermAsGiven bool
+}
+
+// TypoBudget determines the maximum distance allowed between a search term and a key term based on the
+// number of runes in the normalized search term.
+type TypoBudget func(searchTermLength int) int
+
+// DefaultTypoBudget allows no typos for search terms of 1 to 3 characters, 1 typo for 4 to 7 characters,
+// and 2 typos for anything longer.
+func DefaultTypoBudget(searchTermLength int) int {
+	if searchTermLength <= 3 {
+		return 0
+	} else if searchTermLength <= 7 {
+		return 1
+	}
+	return 2
+}
+
+// getMaxDistance gets the maximum distance allowed by these options for a normalized search term of the given length,
+// in runes; if there is no limit, this returns -1.
+// An error wrapping ErrInvalidSearchOptions is returned if the TypoBudget allows a negative distance for that length.
+func (o SearchOptions) getMaxDistance(searchTermLength int) (int, error) {
+	maxDistance := -1
+	if o.MaxDistance != nil {
+		maxDistance = *o.MaxDistance
+	}
+
+	if o.TypoBudget != nil {
+		typoBudget := o.TypoBudget(searchTermLength)
+		if typoBudget < 0 {
+			return 0, fmt.Errorf("typo budget %d for a search term of %d runes is negative: %w", typoBudget, searchTermLength, ErrInvalidSearchOptions)
+		}
+		if maxDistance < 0 || typoBudget < maxDistance {
+			maxDistance = typoBudget
+		}
+	}
+
+	return maxDistance, nil
+}
+
+// validate returns an error wrapping ErrInvalidSearchOptions if these options cannot be applied.
+func (o SearchOptions) validate() error {
+	if o.Limit < 0 {
+		return fmt.Errorf("limit %d is negative: %w", o.Limit, ErrInvalidSearchOptions)
+	}
+	if o.Offset < 0 {
+		return fmt.Errorf("offset %d is negative: %w", o.Offset, ErrInvalidSearchOptions)
+	}
+	if o.MaxDistance != nil && *o.MaxDistance < 0 {
+		return fmt.Errorf("max distance %d is negative: %w", *o.MaxDistance, ErrInvalidSearchOptions)
+	}
+	if !o.MatchMode.isValid() {
+		return fmt.Errorf("match mode %d is not defined: %w", o.MatchMode, ErrInvalidSearchOptions)
+	}
+	if o.PrefixEditBudget < 0 {
+		return fmt.Errorf("prefix edit budget %d is negative: %w", o.PrefixEditBudget, ErrInvalidSearchOptions)
+	}
+	return nil
+}
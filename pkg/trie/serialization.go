@@ -0,0 +1,430 @@
+package trie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"reflect"
+	"unicode/utf8"
+)
+
+// treeFormatMagic identifies a serialized Tree.
+var treeFormatMagic = [4]byte{'F', 'Z', 'T', 'R'}
+
+// treeFormatVersion is the version of the serialized Tree format written and read by this package.
+const treeFormatVersion = 1
+
+var (
+	// ErrInvalidTreeFormat is returned when reading data that is not a serialized Tree.
+	ErrInvalidTreeFormat = errors.New("invalid serialized tree format")
+	// ErrUnsupportedTreeVersion is returned when reading a serialized Tree of a format version this package cannot read.
+	ErrUnsupportedTreeVersion = errors.New("unsupported serialized tree version")
+	// ErrTreeChecksumMismatch is returned when reading a serialized Tree whose contents do not match its checksum.
+	ErrTreeChecksumMismatch = errors.New("serialized tree checksum mismatch")
+)
+
+// ValueCodec encodes and decodes the values held within a Tree for purposes of serializing the tree.
+// If trees are to be searched together in a DistanceTrees instance, DecodeValue must return the same value for each
+// of an item's occurrences across the trees (e.g., by encoding an identifier of the item and looking it up when decoding),
+// as results are combined across trees by the identity of their values.
+type ValueCodec[T any] interface {
+	// EncodeValue encodes the given value.
+	EncodeValue(value T) ([]byte, error)
+	// DecodeValue decodes a value encoded by EncodeValue.
+	DecodeValue(data []byte) (T, error)
+}
+
+// TreeEncoder serializes a Tree, encoding its values with a ValueCodec.
+// It implements both io.WriterTo and encoding.BinaryMarshaler.
+//
+// The serialized format is versioned and checksummed. It consists of a header identifying the format and its version,
+// followed by the length of the body, the body holding the tree's distinct values and then its nodes in depth-first
+// order, and a trailing CRC-32 checksum of the body. The body is checked against its checksum before any of it is read,
+// so the ValueCodec never decodes corrupted data.
+// The tree's KeyTermExtractor, Normalizer and Tokenizer are not serialized and must be supplied again when reading the tree.
+type TreeEncoder[T any] struct {
+	tree  *Tree[T]
+	codec ValueCodec[T]
+}
+
+// Encoder builds a TreeEncoder that serializes this tree, encoding its values with the given codec.
+// The tree must not be modified while it is being serialized.
+func (t *Tree[T]) Encoder(codec ValueCodec[T]) *TreeEncoder[T] {
+	return &TreeEncoder[T]{
+		tree:  t,
+		codec: codec,
+	}
+}
+
+// MarshalBinary serializes the tree.
+func (e *TreeEncoder[T]) MarshalBinary() ([]byte, error) {
+	var buffer bytes.Buffer
+	if _, err := e.WriteTo(&buffer); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// WriteTo serializes the tree into the given writer, returning the number of bytes written.
+func (e *TreeEncoder[T]) WriteTo(w io.Writer) (int64, error) {
+	// The body is built in memory first, as its length precedes it
+	var bodyBuffer bytes.Buffer
+	body := &treeWriter{
+		writer: &bodyBuffer,
+	}
+
+	valueIndexes, err := e.writeValues(body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write values: %w", err)
+	}
+
+	if err := e.writeNodes(body, valueIndexes); err != nil {
+		return 0, fmt.Errorf("failed to write nodes: %w", err)
+	}
+
+	countingWriter := &countingWriter{writer: w}
+	bufferedWriter := bufio.NewWriter(countingWriter)
+
+	if _, err := bufferedWriter.Write(treeFormatMagic[:]); err != nil {
+		return countingWriter.count, fmt.Errorf("failed to write header: %w", err)
+	}
+	if err := bufferedWriter.WriteByte(treeFormatVersion); err != nil {
+		return countingWriter.count, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	var lengthBuffer [binary.MaxVarintLen64]byte
+	if _, err := bufferedWriter.Write(binary.AppendUvarint(lengthBuffer[:0], uint64(bodyBuffer.Len()))); err != nil {
+		return countingWriter.count, fmt.Errorf("failed to write body length: %w", err)
+	}
+	if _, err := bufferedWriter.Write(bodyBuffer.Bytes()); err != nil {
+		return countingWriter.count, fmt.Errorf("failed to write body: %w", err)
+	}
+
+	if err := binary.Write(bufferedWriter, binary.BigEndian, crc32.ChecksumIEEE(bodyBuffer.Bytes())); err != nil {
+		return countingWriter.count, fmt.Errorf("failed to write checksum: %w", err)
+	}
+
+	if err := bufferedWriter.Flush(); err != nil {
+		return countingWriter.count, fmt.Errorf("failed to flush tree: %w", err)
+	}
+
+	return countingWriter.count, nil
+}
+
+// writeValues writes the distinct values of the tree, returning the index at which each node's values were written.
+func (e *TreeEncoder[T]) writeValues(body *treeWriter) (map[*Node[T]][]int, error) {
+	var values []T
+	valueIndexes := make(map[*Node[T]][]int)
+	// distinctValues maps each comparable value to its index, so that values held by multiple nodes are written once
+	distinctValues := make(map[any]int)
+
+	walkErr := e.tree.Walk(func(node *Node[T]) error {
+		for _, value := range node.values {
+			valueIndex := len(values)
+			if isComparableValue(value) {
+				if existingIndex, isExisting := distinctValues[any(value)]; isExisting {
+					valueIndex = existingIndex
+				} else {
+					distinctValues[any(value)] = valueIndex
+				}
+			}
+
+			if valueIndex == len(values) {
+				values = append(values, value)
+			}
+			valueIndexes[node] = append(valueIndexes[node], valueIndex)
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	body.writeUvarint(uint64(len(values)))
+	for valueIndex, value := range values {
+		encodedValue, err := e.codec.EncodeValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode value at index %d: %w", valueIndex, err)
+		}
+		body.writeUvarint(uint64(len(encodedValue)))
+		body.write(encodedValue)
+	}
+
+	return valueIndexes, body.err
+}
+
+// writeNodes writes the nodes of the tree in depth-first order, with each node's children ordered by their runes.
+func (e *TreeEncoder[T]) writeNodes(body *treeWriter, valueIndexes map[*Node[T]][]int) error {
+	walkErr := e.tree.Walk(func(node *Node[T]) error {
+		if node.keyRune != nil {
+			body.writeUvarint(uint64(*node.keyRune))
+		}
+
+		nodeValueIndexes := valueIndexes[node]
+		body.writeUvarint(uint64(len(nodeValueIndexes)))
+		for _, valueIndex := range nodeValueIndexes {
+			body.writeUvarint(uint64(valueIndex))
+		}
+
+		// Nodes of trees not loaded with a Tokenizer have no token positions
+		body.writeUvarint(uint64(len(node.tokenPositions)))
+		for _, tokenPosition := range node.tokenPositions {
+			body.writeUvarint(uint64(tokenPosition))
+		}
+
+		body.writeUvarint(uint64(len(node.children)))
+		return body.err
+	})
+	if walkErr != nil {
+		return walkErr
+	}
+
+	return body.err
+}
+
+// ReadTree reads a Tree serialized by a TreeEncoder, decoding its values with the given codec.
+// The given termExtractor and options are used in the same manner as LoadTree; in particular, the tree must be given
+// the same Normalizer it was loaded with. The termExtractor may be nil if the tree will never be modified.
+func ReadTree[T any](r io.Reader, codec ValueCodec[T], termExtractor KeyTermExtractor[T], options ...LoadOption) (*Tree[T], error) {
+	return ReadTreeMulti(r, codec, termExtractor.toMulti(), options...)
+}
+
+// ReadTreeMulti reads a Tree serialized by a TreeEncoder, decoding its values with the given codec.
+// The given termExtractor and options are used in the same manner as LoadTreeMulti; see ReadTree for more details.
+func ReadTreeMulti[T any](r io.Reader, codec ValueCodec[T], termExtractor MultiKeyTermExtractor[T], options ...LoadOption) (*Tree[T], error) {
+	bufferedReader := bufio.NewReader(r)
+
+	var magic [4]byte
+	if _, err := io.ReadFull(bufferedReader, magic[:]); err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", errors.Join(ErrInvalidTreeFormat, err))
+	} else if magic != treeFormatMagic {
+		return nil, fmt.Errorf("unrecognized header %q: %w", magic[:], ErrInvalidTreeFormat)
+	}
+
+	version, err := bufferedReader.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version: %w", errors.Join(ErrInvalidTreeFormat, err))
+	} else if version != treeFormatVersion {
+		return nil, fmt.Errorf("version %d: %w", version, ErrUnsupportedTreeVersion)
+	}
+
+	bodyLength, err := binary.ReadUvarint(bufferedReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body length: %w", errors.Join(ErrInvalidTreeFormat, err))
+	}
+	bodyData, err := readBytes(bufferedReader, bodyLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+
+	// The checksum is verified before the body is read, so that corrupted values never reach the codec
+	expectedChecksum := crc32.ChecksumIEEE(bodyData)
+	var actualChecksum uint32
+	if err := binary.Read(bufferedReader, binary.BigEndian, &actualChecksum); err != nil {
+		return nil, fmt.Errorf("failed to read checksum: %w", errors.Join(ErrInvalidTreeFormat, err))
+	} else if actualChecksum != expectedChecksum {
+		return nil, fmt.Errorf("expected checksum %08x, got %08x: %w", expectedChecksum, actualChecksum, ErrTreeChecksumMismatch)
+	}
+
+	body := &treeReader{
+		reader: bytes.NewReader(bodyData),
+	}
+
+	values, err := readValues(body, codec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values: %w", err)
+	}
+
+	rootNode, err := readNodes(body, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nodes: %w", err)
+	}
+
+	if body.reader.Len() > 0 {
+		return nil, fmt.Errorf("%d unread bytes at the end of the body: %w", body.reader.Len(), ErrInvalidTreeFormat)
+	}
+
+	return newTree(rootNode, termExtractor, buildLoadOptions(options)), nil
+}
+
+// readValues reads the distinct values of a serialized tree.
+func readValues[T any](body *treeReader, codec ValueCodec[T]) ([]T, error) {
+	valueCount := body.readUvarint()
+	if body.err != nil {
+		return nil, body.err
+	}
+
+	var values []T
+	for valueIndex := uint64(0); valueIndex < valueCount; valueIndex++ {
+		encodedValue := body.readBytes(body.readUvarint())
+		if body.err != nil {
+			return nil, body.err
+		}
+
+		value, err := codec.DecodeValue(encodedValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode value at index %d: %w", valueIndex, err)
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// readNodes reads the nodes of a serialized tree, returning its root node.
+func readNodes[T any](body *treeReader, values []T) (*Node[T], error) {
+	// pendingNode is a node whose children have not all been read yet
+	type pendingNode struct {
+		node              *Node[T]
+		remainingChildren uint64
+	}
+
+	readNode := func(parentNode *Node[T], keyRune *rune) (*pendingNode, error) {
+		node := newTrieNode[T](parentNode, keyRune)
+		valueCount := body.readUvarint()
+		for valueIndex := uint64(0); valueIndex < valueCount && body.err == nil; valueIndex++ {
+			valueReference := body.readUvarint()
+			if valueReference >= uint64(len(values)) {
+				return nil, fmt.Errorf("value reference %d out of range: %w", valueReference, ErrInvalidTreeFormat)
+			}
+			node.values = append(node.values, values[valueReference])
+		}
+
+		if len(node.values) > 0 {
+			node.keyTerm = node.buildKeyTerm()
+		}
+
+		tokenPositionCount := body.readUvarint()
+		if tokenPositionCount != 0 && tokenPositionCount != valueCount {
+			return nil, fmt.Errorf("%d token positions for %d values: %w", tokenPositionCount, valueCount, ErrInvalidTreeFormat)
+		}
+		for positionIndex := uint64(0); positionIndex < tokenPositionCount && body.err == nil; positionIndex++ {
+			node.tokenPositions = append(node.tokenPositions, int(body.readUvarint()))
+		}
+
+		return &pendingNode{
+			node:              node,
+			remainingChildren: body.readUvarint(),
+		}, body.err
+	}
+
+	root, err := readNode(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each child is read directly after its parent, so the node whose children are being read is the last one pending
+	pendingNodes := []*pendingNode{root}
+	for len(pendingNodes) > 0 {
+		parent := pendingNodes[len(pendingNodes)-1]
+		if parent.remainingChildren == 0 {
+			pendingNodes = pendingNodes[:len(pendingNodes)-1]
+			continue
+		}
+		parent.remainingChildren--
+
+		childRuneValue := body.readUvarint()
+		if body.err != nil {
+			return nil, body.err
+		} else if childRuneValue > utf8.MaxRune || !utf8.ValidRune(rune(childRuneValue)) {
+			return nil, fmt.Errorf("invalid rune %d: %w", childRuneValue, ErrInvalidTreeFormat)
+		}
+
+		childRune := rune(childRuneValue)
+		if _, isDuplicate := parent.node.children[childRune]; isDuplicate {
+			return nil, fmt.Errorf("duplicate child rune %q: %w", childRune, ErrInvalidTreeFormat)
+		}
+
+		child, err := readNode(parent.node, &childRune)
+		if err != nil {
+			return nil, err
+		}
+		parent.node.children[childRune] = child.node
+		pendingNodes = append(pendingNodes, child)
+	}
+
+	return root.node, nil
+}
+
+// isComparableValue determines if the given value can be compared with the == operator without panicking.
+func isComparableValue(value any) bool {
+	valueType := reflect.TypeOf(value)
+	return valueType == nil || valueType.Comparable()
+}
+
+// treeWriter writes the body of a serialized tree, retaining the first error encountered.
+type treeWriter struct {
+	writer io.Writer
+	buffer [binary.MaxVarintLen64]byte
+	err    error
+}
+
+func (w *treeWriter) write(data []byte) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = w.writer.Write(data)
+}
+
+func (w *treeWriter) writeUvarint(value uint64) {
+	length := binary.PutUvarint(w.buffer[:], value)
+	w.write(w.buffer[:length])
+}
+
+// treeReader reads the body of a serialized tree, retaining the first error encountered.
+type treeReader struct {
+	reader *bytes.Reader
+	err    error
+}
+
+func (r *treeReader) readUvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+
+	value, err := binary.ReadUvarint(r.reader)
+	if err != nil {
+		r.err = errors.Join(ErrInvalidTreeFormat, err)
+	}
+	return value
+}
+
+func (r *treeReader) readBytes(length uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	data, err := readBytes(r.reader, length)
+	if err != nil {
+		r.err = err
+	}
+	return data
+}
+
+// readBytes reads the given number of bytes from the given reader.
+func readBytes(r io.Reader, length uint64) ([]byte, error) {
+	// Read through a limited reader rather than allocating the full length up front, in case the length is corrupt
+	var data bytes.Buffer
+	if _, err := data.ReadFrom(io.LimitReader(r, int64(min(length, uint64(1)<<62)))); err != nil {
+		return nil, errors.Join(ErrInvalidTreeFormat, err)
+	} else if uint64(data.Len()) != length {
+		return nil, errors.Join(ErrInvalidTreeFormat, io.ErrUnexpectedEOF)
+	}
+	return data.Bytes(), nil
+}
+
+// countingWriter counts the number of bytes written through it.
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+func (w *countingWriter) Write(data []byte) (int, error) {
+	written, err := w.writer.Write(data)
+	w.count += int64(written)
+	return written, err
+}
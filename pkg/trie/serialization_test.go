@@ -0,0 +1,121 @@
+package trie_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/coinbase/fuzzy-trie/pkg/trie"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"strings"
+)
+
+var _ = Describe("Serialization", func() {
+	var ctx context.Context
+	var tree *trie.Tree[*testComparableFuzzable]
+
+	searchTexts := func(tree *trie.Tree[*testComparableFuzzable], searchTerm string) []string {
+		results, err := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{tree}).Search(ctx, searchTerm)
+		Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+		texts := make([]string, len(results))
+		for i, result := range results {
+			texts[i] = result.Result.text
+		}
+		return texts
+	}
+
+	BeforeEach(func() {
+		ctx = newTestContext()
+		tree = loadTestTree(ctx, newTestAnimals())
+	})
+
+	It("should produce identical search results after a round trip", func() {
+		var buffer bytes.Buffer
+		written, err := tree.Encoder(&testValueCodec{}).WriteTo(&buffer)
+		Expect(err).ToNot(HaveOccurred(), "writing the tree should not fail")
+		Expect(written).To(BeEquivalentTo(buffer.Len()), "the number of bytes written should be reported")
+
+		readTree, err := trie.ReadTree(bytes.NewReader(buffer.Bytes()), &testValueCodec{}, textTermExtractor)
+		Expect(err).ToNot(HaveOccurred(), "reading the tree should not fail")
+		Expect(readTree.GetLeafNodes()).To(HaveLen(len(tree.GetLeafNodes())), "the leaf nodes should be restored")
+
+		for _, searchTerm := range []string{"cat", "wol", "e", "domestic"} {
+			Expect(searchTexts(readTree, searchTerm)).To(Equal(searchTexts(tree, searchTerm)), "the results for '%s' should be identical", searchTerm)
+		}
+
+		Expect(readTree.Insert(ctx, newTestComparableFuzzable("Unicorn"))).To(Succeed(), "the read tree should be modifiable")
+		Expect(searchTexts(readTree, "unicorn")).To(Equal([]string{"Unicorn"}), "the inserted item should be found")
+	})
+
+	It("should marshal to the same bytes as it writes", func() {
+		var buffer bytes.Buffer
+		_, err := tree.Encoder(&testValueCodec{}).WriteTo(&buffer)
+		Expect(err).ToNot(HaveOccurred(), "writing the tree should not fail")
+
+		marshaled, err := tree.Encoder(&testValueCodec{}).MarshalBinary()
+		Expect(err).ToNot(HaveOccurred(), "marshaling the tree should not fail")
+		Expect(marshaled).To(Equal(buffer.Bytes()), "the output should be deterministic")
+	})
+
+	It("should write values held by multiple nodes once", func() {
+		cat := newTestComparableFuzzable("Cat")
+		multiTree := loadTestTree(ctx, []*testComparableFuzzable{cat, cat})
+
+		codec := &testValueCodec{}
+		marshaled, err := multiTree.Encoder(codec).MarshalBinary()
+		Expect(err).ToNot(HaveOccurred(), "marshaling the tree should not fail")
+		Expect(codec.encodeCount).To(Equal(1), "the shared value should be encoded once")
+
+		readTree, err := trie.ReadTree(bytes.NewReader(marshaled), codec, textTermExtractor)
+		Expect(err).ToNot(HaveOccurred(), "reading the tree should not fail")
+		values := readTree.GetLeafNodes()[0].GetValues()
+		Expect(values).To(HaveLen(2), "both occurrences should be restored")
+		Expect(values[0]).To(BeIdenticalTo(values[1]), "both occurrences should share the decoded value")
+	})
+
+	It("should reject corrupted data", func() {
+		marshaled, err := tree.Encoder(&testValueCodec{}).MarshalBinary()
+		Expect(err).ToNot(HaveOccurred(), "marshaling the tree should not fail")
+
+		corrupted := bytes.Clone(marshaled)
+		corrupted[len(corrupted)/2] ^= 0xFF
+		codec := &testValueCodec{}
+		_, err = trie.ReadTree(bytes.NewReader(corrupted), codec, textTermExtractor)
+		Expect(err).To(MatchError(trie.ErrTreeChecksumMismatch), "a corrupted body should be detected")
+		Expect(codec.decodeCount).To(BeZero(), "no value of a corrupted body should be decoded")
+
+		corrupted = bytes.Clone(marshaled)
+		corrupted[len(corrupted)-1] ^= 0xFF
+		_, err = trie.ReadTree(bytes.NewReader(corrupted), &testValueCodec{}, textTermExtractor)
+		Expect(err).To(MatchError(trie.ErrTreeChecksumMismatch), "a mismatched checksum should be detected")
+
+		_, err = trie.ReadTree(bytes.NewReader(marshaled[:len(marshaled)/2]), &testValueCodec{}, textTermExtractor)
+		Expect(err).To(MatchError(trie.ErrInvalidTreeFormat), "truncated data should be detected")
+	})
+
+	It("should reject data that is not a serialized tree", func() {
+		_, err := trie.ReadTree(strings.NewReader("not a tree"), &testValueCodec{}, textTermExtractor)
+		Expect(err).To(MatchError(trie.ErrInvalidTreeFormat), "the header should be checked")
+
+		marshaled, err := tree.Encoder(&testValueCodec{}).MarshalBinary()
+		Expect(err).ToNot(HaveOccurred(), "marshaling the tree should not fail")
+		marshaled[4] = 99
+		_, err = trie.ReadTree(bytes.NewReader(marshaled), &testValueCodec{}, textTermExtractor)
+		Expect(err).To(MatchError(trie.ErrUnsupportedTreeVersion), "the version should be checked")
+	})
+})
+
+// testValueCodec encodes testComparableFuzzable values as their text
+type testValueCodec struct {
+	encodeCount int
+	decodeCount int
+}
+
+func (c *testValueCodec) EncodeValue(value *testComparableFuzzable) ([]byte, error) {
+	c.encodeCount++
+	return []byte(value.text), nil
+}
+
+func (c *testValueCodec) DecodeValue(data []byte) (*testComparableFuzzable, error) {
+	c.decodeCount++
+	return newTestComparableFuzzable(string(data)), nil
+}
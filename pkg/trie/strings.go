@@ -1,8 +1,18 @@
 package trie
 
-import "strings"
+import "unicode/utf8"
 
-// normalizeTerm normalizes the given term to a format that ensures consistency of storage
+// normalizeTerm normalizes the given term to a format that ensures consistency of storage using the default Normalizer
 func normalizeTerm(v string) string {
-	return strings.ToUpper(v)
+	return UpperCaseNormalizer.Normalize(v)
+}
+
+// exceedsDistance determines if the Levenshtein distance between the two given terms is certain to exceed the given
+// maximum distance without calculating it, as the distance can never be smaller than the difference in the terms' lengths.
+func exceedsDistance(a, b string, maxDistance int) bool {
+	lengthDifference := utf8.RuneCountInString(a) - utf8.RuneCountInString(b)
+	if lengthDifference < 0 {
+		lengthDifference = -lengthDifference
+	}
+	return lengthDifference > maxDistance
 }
@@ -0,0 +1,111 @@
+package trie
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenSeparator separates the normalized tokens of the key terms of a tree loaded with a Tokenizer.
+const tokenSeparator = " "
+
+// Tokenizer splits terms into the tokens (e.g., words) that they consist of.
+// Implementations must be safe for concurrent use.
+type Tokenizer interface {
+	// Tokenize splits the given term into its tokens, in the order they appear within the term.
+	Tokenize(term string) []string
+}
+
+// TokenizerFunc is a function implementation of the Tokenizer interface.
+type TokenizerFunc func(term string) []string
+
+func (f TokenizerFunc) Tokenize(term string) []string {
+	return f(term)
+}
+
+// WordTokenizer splits terms into words at whitespace and punctuation, at the boundaries of camel-cased words (e.g.,
+// "camelCase" becomes "camel" and "Case") and between letters and digits (e.g., "web3" becomes "web" and "3").
+var WordTokenizer Tokenizer = TokenizerFunc(tokenizeWords)
+
+// tokenizeWords splits the given term into its words.
+func tokenizeWords(term string) []string {
+	var tokens []string
+	var currentToken strings.Builder
+	var previousRune rune
+	for _, currentRune := range term {
+		if isSeparator(currentRune) {
+			tokens = appendToken(tokens, &currentToken)
+		} else {
+			if currentToken.Len() > 0 && isTokenBoundary(previousRune, currentRune) {
+				tokens = appendToken(tokens, &currentToken)
+			}
+			currentToken.WriteRune(currentRune)
+		}
+		previousRune = currentRune
+	}
+
+	return appendToken(tokens, &currentToken)
+}
+
+// appendToken appends the contents of the given builder, if any, to the given tokens, resetting the builder.
+func appendToken(tokens []string, token *strings.Builder) []string {
+	if token.Len() == 0 {
+		return tokens
+	}
+
+	tokens = append(tokens, token.String())
+	token.Reset()
+	return tokens
+}
+
+// isTokenBoundary determines if a token ends between the given adjacent runes of a word.
+func isTokenBoundary(previousRune rune, currentRune rune) bool {
+	if unicode.IsLower(previousRune) && unicode.IsUpper(currentRune) {
+		return true
+	}
+	return unicode.IsDigit(previousRune) != unicode.IsDigit(currentRune)
+}
+
+// tokenizeKeyTerm splits the given term into its tokens using the given tokenizer, normalizing each with the given
+// normalizer, and builds a key term from each token onwards to the end of the term.
+func tokenizeKeyTerm(term string, tokenizer Tokenizer, normalizer Normalizer) []keyTermPlacement {
+	normalizedTokens := normalizeTokens(term, tokenizer, normalizer)
+	keyTerms := make([]keyTermPlacement, len(normalizedTokens))
+	for tokenPosition := range normalizedTokens {
+		keyTerms[tokenPosition] = keyTermPlacement{
+			keyTerm:       strings.Join(normalizedTokens[tokenPosition:], tokenSeparator),
+			tokenPosition: tokenPosition,
+		}
+	}
+	return keyTerms
+}
+
+// normalizeTokens splits the given term into its tokens using the given tokenizer, normalizing each with the given
+// normalizer and discarding any left empty.
+func normalizeTokens(term string, tokenizer Tokenizer, normalizer Normalizer) []string {
+	tokens := tokenizer.Tokenize(term)
+	normalizedTokens := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if normalizedToken := normalizer.Normalize(token); normalizedToken != "" {
+			normalizedTokens = append(normalizedTokens, normalizedToken)
+		}
+	}
+	return normalizedTokens
+}
+
+// leadingTokens gets the given number of leading tokens of the given normalized tokenized term, or the whole term if
+// it has no more than that many tokens.
+func leadingTokens(tokenizedTerm string, tokenCount int) string {
+	if tokenCount <= 0 {
+		return ""
+	}
+
+	endIndex := 0
+	for tokenIndex := 0; tokenIndex < tokenCount; tokenIndex++ {
+		separatorIndex := strings.Index(tokenizedTerm[endIndex:], tokenSeparator)
+		if separatorIndex < 0 {
+			return tokenizedTerm
+		}
+		endIndex += separatorIndex + len(tokenSeparator)
+	}
+	return tokenizedTerm[:endIndex-len(tokenSeparator)]
+}
@@ -0,0 +1,101 @@
+package trie_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/coinbase/fuzzy-trie/pkg/trie"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tokenizer", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = newTestContext()
+	})
+
+	DescribeTable("word tokenizer",
+		func(term string, expected []string) {
+			Expect(trie.WordTokenizer.Tokenize(term)).To(Equal(expected), "the term should be tokenized")
+		},
+		Entry("whitespace", "Domestic  Bactrian camel", []string{"Domestic", "Bactrian", "camel"}),
+		Entry("punctuation", "wild-cat, domestic", []string{"wild", "cat", "domestic"}),
+		Entry("camel case", "fuzzyTrieNode", []string{"fuzzy", "Trie", "Node"}),
+		Entry("digits", "web3wallet 2024", []string{"web", "3", "wallet", "2024"}),
+		Entry("no tokens", " -- ", []string(nil)),
+	)
+
+	Context("tokenized trees", func() {
+		var animalsTree *trie.Tree[*testComparableFuzzable]
+
+		BeforeEach(func() {
+			animalsTree = loadTestTree(ctx, newTestAnimals(), trie.WithTokenizer(trie.WordTokenizer, 0.5))
+		})
+
+		It("should match words in the middle of a term", func() {
+			results, err := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{animalsTree}).SearchWithOptions(ctx, "bactrian", trie.SearchOptions{})
+			Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+			Expect(results).ToNot(BeEmpty(), "the item should be found")
+			Expect(results[0].Result.text).To(Equal("Domestic Bactrian camel"), "the item should be the best match")
+			Expect(results[0].Matches[0].KeyTerm).To(Equal("BACTRIAN CAMEL"), "the item should match from its second word")
+			Expect(results[0].Matches[0].TokenPosition).To(Equal(1), "the position of the matched word should be returned")
+			Expect(results[0].Matches[0].Distance).To(Equal(0.5), "the distance should be penalized by the position of the word")
+		})
+
+		It("should rank matches at the start of a term first", func() {
+			results, err := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{animalsTree}).SearchWithOptions(ctx, "cat", trie.SearchOptions{
+				MaxDistance: new(int),
+			})
+			Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+			Expect(results).ToNot(BeEmpty(), "the items should be found")
+			Expect(results[0].Result.text).To(Equal("Cat"), "the exact match should be ranked first")
+			for _, result := range results {
+				if result.Result.text == "Cat" {
+					continue
+				}
+				Expect(result.Matches[0].TokenPosition).To(BeNumerically(">", 0), "'%s' should be matched by a later word", result.Result.text)
+			}
+		})
+
+		It("should match multiple words against the leading words of a key term", func() {
+			results, err := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{animalsTree}).SearchWithOptions(ctx, "bactrian-camel", trie.SearchOptions{})
+			Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+			Expect(results).ToNot(BeEmpty(), "the item should be found")
+			Expect(results[0].Result.text).To(Equal("Domestic Bactrian camel"), "the item should be the best match")
+			Expect(results[0].Matches[0].Distance).To(Equal(0.5), "the words should match exactly")
+		})
+
+		It("should place inserted items under each of their words and remove them from each", func() {
+			fennec := newTestComparableFuzzable("Desert fennec fox")
+			Expect(animalsTree.Insert(ctx, fennec)).To(Succeed(), "inserting should not fail")
+
+			distanceTrees := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{animalsTree})
+			results, err := distanceTrees.Search(ctx, "fennec")
+			Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+			Expect(results).ToNot(BeEmpty(), "the inserted item should be found")
+			Expect(results[0].Result).To(Equal(fennec), "the inserted item should be found by its second word")
+
+			Expect(animalsTree.Remove(ctx, fennec)).To(Succeed(), "removing should not fail")
+			results, err = distanceTrees.Search(ctx, "fennec")
+			Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+			for _, result := range results {
+				Expect(result.Result).ToNot(Equal(fennec), "the removed item should not be found")
+			}
+		})
+
+		It("should retain the positions of words after a round trip", func() {
+			marshaled, err := animalsTree.Encoder(&testValueCodec{}).MarshalBinary()
+			Expect(err).ToNot(HaveOccurred(), "marshaling the tree should not fail")
+
+			readTree, err := trie.ReadTree(bytes.NewReader(marshaled), &testValueCodec{}, textTermExtractor, trie.WithTokenizer(trie.WordTokenizer, 0.5))
+			Expect(err).ToNot(HaveOccurred(), "reading the tree should not fail")
+
+			results, err := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{readTree}).SearchWithOptions(ctx, "bactrian", trie.SearchOptions{})
+			Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+			Expect(results).ToNot(BeEmpty(), "the item should be found")
+			Expect(results[0].Result.text).To(Equal("Domestic Bactrian camel"), "the item should be the best match")
+			Expect(results[0].Matches[0].Distance).To(Equal(0.5), "the position of the word should be restored")
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package trie
+
+import (
+	"errors"
+	"slices"
+)
+
+// SkipChildren is returned by the visit function given to Tree.Walk to skip the descendants of the node being visited.
+// It is not returned as an error by Walk.
+var SkipChildren = errors.New("skip children")
+
+// Root gets the root node of this tree, whose key term is empty.
+func (t *Tree[T]) Root() *Node[T] {
+	return t.root
+}
+
+// Walk visits every node of this tree in depth-first order, starting from the root, with each node's children visited
+// in the order of their runes.
+// If the given visit function returns SkipChildren, the descendants of the node are not visited; if it returns any
+// other error, the walk stops and that error is returned.
+// The tree must not be modified during the walk.
+func (t *Tree[T]) Walk(visit func(node *Node[T]) error) error {
+	// The pending nodes form a stack, so each node's descendants are visited before its later siblings
+	pendingNodes := []*Node[T]{t.root}
+	for len(pendingNodes) > 0 {
+		node := pendingNodes[len(pendingNodes)-1]
+		pendingNodes = pendingNodes[:len(pendingNodes)-1]
+
+		if err := visit(node); errors.Is(err, SkipChildren) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		// Push the children in descending order so that they are visited in ascending order
+		childNodes := node.Children()
+		slices.Reverse(childNodes)
+		pendingNodes = append(pendingNodes, childNodes...)
+	}
+
+	return nil
+}
+
+// FindPrefix finds the node whose key term is the given prefix, once normalized with Tree.Normalize, returning nil if
+// no key term in this tree begins with the prefix.
+// The key terms of the node's values and of the values of all its descendants begin with the prefix.
+func (t *Tree[T]) FindPrefix(prefix string) *Node[T] {
+	currentNode := t.root
+	for _, prefixRune := range t.Normalize(prefix) {
+		if currentNode = currentNode.children[prefixRune]; currentNode == nil {
+			return nil
+		}
+	}
+	return currentNode
+}
+
+// Children gets the child nodes of this node, ordered by their runes.
+func (n *Node[T]) Children() []*Node[T] {
+	childRunes := make([]rune, 0, len(n.children))
+	for childRune := range n.children {
+		childRunes = append(childRunes, childRune)
+	}
+	slices.Sort(childRunes)
+
+	childNodes := make([]*Node[T], len(childRunes))
+	for childIndex, childRune := range childRunes {
+		childNodes[childIndex] = n.children[childRune]
+	}
+	return childNodes
+}
+
+// GetChild gets the child node of this node for the given rune, which is expected to be normalized, or nil if there
+// is none.
+func (n *Node[T]) GetChild(childRune rune) *Node[T] {
+	return n.children[childRune]
+}
+
+// GetKeyRune gets the last rune of this node's key term, returning false if this is the root node.
+func (n *Node[T]) GetKeyRune() (rune, bool) {
+	if n.keyRune == nil {
+		return 0, false
+	}
+	return *n.keyRune, true
+}
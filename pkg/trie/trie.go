@@ -2,8 +2,10 @@ package trie
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
+	"strings"
 )
 
 // Node defines a node participating in a trie tree
@@ -12,16 +14,32 @@ type Node[T any] struct {
 	parent   *Node[T]
 	children map[rune]*Node[T]
 	values   []T
+	// tokenPositions holds the position of the leading token of this node's key term within the term each of the values
+	// was placed under, in the order of the values; it is only populated in trees loaded with a Tokenizer
+	tokenPositions []int
+	// keyTerm caches the key term of this node once it holds values, so that it need not be rebuilt from the node's
+	// ancestors whenever the node is evaluated
+	keyTerm string
+	// leafIndex is the index of this node within its tree's leaf nodes, or -1 if this is not a leaf node
+	leafIndex int
 }
 
-// Tree defines a trie tree that only allows bottom-up traversal.
+// Tree defines a trie tree that is searched by bottom-up traversal.
 // This is structured as such because it is assumed that, if a search term does not sufficiently exist in a particular node,
 // then it will not sufficiently exist in parent nodes (e.g., if searching for 'o' and the current node is "BTC", then
 // the parent nodes "BT" and "B" will never satisfy the search term, either).
 // This allows the traversal of the tree to terminate and discard consideration of entire ancestries of nodes in the trie
 // tree.
+// The tree can also be traversed top-down from its Root, such as with Walk and FindPrefix.
 type Tree[T any] struct {
-	leafNodes []*Node[T]
+	root          *Node[T]
+	leafNodes     []*Node[T]
+	termExtractor MultiKeyTermExtractor[T]
+	normalizer    Normalizer
+	name          string
+	// tokenizer splits the key terms of the tree into tokens, if the tree was loaded with one
+	tokenizer            Tokenizer
+	tokenPositionPenalty float64
 }
 
 // GetLeafNodes gets all the leaf nodes of the tree.
@@ -32,42 +50,176 @@ func (t *Tree[T]) GetLeafNodes() []*Node[T] {
 // KeyTermExtractor is a function used to, while loading a Node tree, extract the tree placement term from a given value.
 type KeyTermExtractor[T any] func(context.Context, T) (string, error)
 
+// MultiKeyTermExtractor is a function used to, while loading a Node tree, extract all the tree placement terms from a
+// given value, such as its name and any aliases of it.
+type MultiKeyTermExtractor[T any] func(context.Context, T) ([]string, error)
+
 // LoadTree builds a Trie tree from the given items, using the given termExtractor to extract the tree placement term from each item.
-func LoadTree[T any](ctx context.Context, items []T, termExtractor KeyTermExtractor[T]) (*Tree[T], error) {
+func LoadTree[T any](ctx context.Context, items []T, termExtractor KeyTermExtractor[T], options ...LoadOption) (*Tree[T], error) {
+	return LoadTreeMulti(ctx, items, termExtractor.toMulti(), options...)
+}
+
+// LoadTreeMulti builds a Trie tree from the given items, using the given termExtractor to extract the tree placement
+// terms from each item and placing the item under each of them.
+// An item placed under multiple terms is returned once by a search of the tree, at its closest distance across the terms.
+func LoadTreeMulti[T any](ctx context.Context, items []T, termExtractor MultiKeyTermExtractor[T], options ...LoadOption) (*Tree[T], error) {
+	builtOptions := buildLoadOptions(options)
+
 	rootNode := newTrieNode[T](nil, nil)
 	for _, item := range items {
-		itemKeyTerm, err := termExtractor(ctx, item)
+		placements, err := extractKeyTerms(ctx, item, termExtractor, builtOptions.normalizer, builtOptions.tokenizer)
+		if err != nil {
+			return nil, err
+		}
+		for _, placement := range placements {
+			rootNode.addItem([]rune(placement.keyTerm), placement.keyTerm, item, placement.tokenPosition)
+		}
+	}
+
+	return newTree(rootNode, termExtractor, builtOptions), nil
+}
+
+// toMulti converts this KeyTermExtractor into a MultiKeyTermExtractor that extracts the single term; a nil
+// KeyTermExtractor converts to a nil MultiKeyTermExtractor.
+func (e KeyTermExtractor[T]) toMulti() MultiKeyTermExtractor[T] {
+	if e == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, item T) ([]string, error) {
+		itemKeyTerm, err := e(ctx, item)
 		if err != nil {
-			return nil, fmt.Errorf("failed to extract term from item: %w", err)
+			return nil, err
+		}
+		return []string{itemKeyTerm}, nil
+	}
+}
+
+// keyTermPlacement is a normalized key term under which an item is placed in a tree.
+type keyTermPlacement struct {
+	keyTerm string
+	// tokenPosition is the position of the leading token of the key term within the term it was built from, or -1 if
+	// the tree was not loaded with a Tokenizer
+	tokenPosition int
+}
+
+// extractKeyTerms extracts the distinct normalized key terms under which the given item is to be placed in a tree.
+// If a tokenizer is given, a key term is built from each token of each extracted term onwards.
+func extractKeyTerms[T any](
+	ctx context.Context,
+	item T,
+	termExtractor MultiKeyTermExtractor[T],
+	normalizer Normalizer,
+	tokenizer Tokenizer,
+) ([]keyTermPlacement, error) {
+	if termExtractor == nil {
+		return nil, errors.New("no term extractor was given for the tree")
+	}
+
+	itemKeyTerms, err := termExtractor(ctx, item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract term from item: %w", err)
+	}
+
+	placements := make([]keyTermPlacement, 0, len(itemKeyTerms))
+	addPlacement := func(placement keyTermPlacement) {
+		// Terms that only differ before normalization lead to the same node, where the item only needs to be placed once
+		for _, existingPlacement := range placements {
+			if existingPlacement.keyTerm == placement.keyTerm {
+				return
+			}
+		}
+		placements = append(placements, placement)
+	}
+
+	for _, itemKeyTerm := range itemKeyTerms {
+		if tokenizer == nil {
+			addPlacement(keyTermPlacement{
+				keyTerm:       normalizer.Normalize(itemKeyTerm),
+				tokenPosition: -1,
+			})
+			continue
+		}
+
+		for _, placement := range tokenizeKeyTerm(itemKeyTerm, tokenizer, normalizer) {
+			addPlacement(placement)
 		}
-		rootNode.addItem([]rune(normalizeTerm(itemKeyTerm)), item)
 	}
 
+	return placements, nil
+}
+
+// newTree builds a Tree around the given fully-populated root node.
+func newTree[T any](rootNode *Node[T], termExtractor MultiKeyTermExtractor[T], options *loadOptions) *Tree[T] {
 	leafNodes := rootNode.getLeafNodes()
+	for leafIndex, leafNode := range leafNodes {
+		leafNode.leafIndex = leafIndex
+	}
 
 	return &Tree[T]{
-		leafNodes: leafNodes,
-	}, nil
+		root:                 rootNode,
+		leafNodes:            leafNodes,
+		termExtractor:        termExtractor,
+		normalizer:           options.normalizer,
+		name:                 options.name,
+		tokenizer:            options.tokenizer,
+		tokenPositionPenalty: options.tokenPositionPenalty,
+	}
+}
+
+// Name gets the name given to this tree with WithTreeName, if any.
+func (t *Tree[T]) Name() string {
+	return t.name
+}
+
+// Normalize normalizes the given term using the Normalizer of this tree.
+// If the tree was loaded with a Tokenizer, the term is split into its tokens, which are normalized individually and
+// joined by single spaces, in the same manner as the key terms of the tree.
+func (t *Tree[T]) Normalize(term string) string {
+	if t.tokenizer != nil {
+		return strings.Join(normalizeTokens(term, t.tokenizer, t.normalizer), tokenSeparator)
+	}
+	return t.normalizer.Normalize(term)
 }
 
 func newTrieNode[T any](parentNode *Node[T], keyRune *rune) *Node[T] {
 	return &Node[T]{
-		parent:   parentNode,
-		children: make(map[rune]*Node[T]),
-		keyRune:  keyRune,
+		parent:    parentNode,
+		children:  make(map[rune]*Node[T]),
+		keyRune:   keyRune,
+		leafIndex: -1,
 	}
 }
 
 // Contains determines if the key term of this node contains the characters of
 // the given phrase in the order given. The key term does not need to contiguously contain
 // the phrase's characters - e.g., if the phrase is 'cal', this will return true if the key term is 'catapult'.
+// The phrase is normalized using the default Normalizer; for nodes of a tree loaded with a different Normalizer, the
+// phrase should be normalized with Tree.Normalize and given to ContainsNormalized instead.
+// To match the same phrase against many nodes, prepare it once with Tree.PrepareQuery and give it to ContainsQuery,
+// which avoids normalizing it again for every node.
 func (n *Node[T]) Contains(phrase string) bool {
-	normalizedPhrase := normalizeTerm(phrase)
-	normalizedRunes := []rune(normalizedPhrase)
+	return n.ContainsNormalized(normalizeTerm(phrase))
+}
+
+// ContainsNormalized determines if the key term of this node contains the characters of the given already-normalized
+// phrase in the order given. See Contains for more details.
+func (n *Node[T]) ContainsNormalized(normalizedPhrase string) bool {
+	return n.containsRunes([]rune(normalizedPhrase))
+}
 
+// containsRunes determines if the key term of this node contains the given normalized runes in the order given.
+func (n *Node[T]) containsRunes(normalizedRunes []rune) bool {
+	return n.matchRunes(normalizedRunes, nil)
+}
+
+// matchRunes determines if the key term of this node contains the given normalized runes in the order given.
+// If the given offsets are non-nil, they must be as long as the runes, and each rune's offset is set to the number of
+// runes between it and the end of the key term, matching each rune to its last occurrence before the runes following it.
+func (n *Node[T]) matchRunes(normalizedRunes []rune, offsetsFromEnd []int) bool {
 	currentNode := n
 	runeIndex := len(normalizedRunes) - 1
-	for {
+	for offsetFromEnd := 0; ; offsetFromEnd++ {
 		if currentNode == nil || currentNode.keyRune == nil || runeIndex < 0 {
 			break
 		}
@@ -76,6 +228,9 @@ func (n *Node[T]) Contains(phrase string) bool {
 
 		// If the current node has the current rune being examined, proceed onto the next rune
 		if *currentNode.keyRune == currentRune {
+			if offsetsFromEnd != nil {
+				offsetsFromEnd[runeIndex] = offsetFromEnd
+			}
 			runeIndex--
 		}
 
@@ -94,6 +249,14 @@ func (n *Node[T]) GetParentNode() *Node[T] {
 
 // GetKeyTerm gets the string value for which this node represents a word in the tree
 func (n *Node[T]) GetKeyTerm() string {
+	if n.keyTerm != "" {
+		return n.keyTerm
+	}
+	return n.buildKeyTerm()
+}
+
+// buildKeyTerm builds the key term of this node from the runes of the node and its ancestors.
+func (n *Node[T]) buildKeyTerm() string {
 	if n.keyRune == nil {
 		return ""
 	}
@@ -117,9 +280,11 @@ func (n *Node[T]) GetValues() []T {
 	return n.values
 }
 
-func (n *Node[T]) addItem(runes []rune, item T) {
+// addItem adds the given item beneath this node at the given remaining runes of the given key term.
+func (n *Node[T]) addItem(runes []rune, keyTerm string, item T, tokenPosition int) {
 	if len(runes) == 0 {
-		n.values = append(n.values, item)
+		n.keyTerm = keyTerm
+		n.addValue(item, tokenPosition)
 		return
 	}
 
@@ -128,7 +293,23 @@ func (n *Node[T]) addItem(runes []rune, item T) {
 		n.children[firstRune] = newTrieNode[T](n, &runes[0])
 	}
 
-	n.children[firstRune].addItem(runes[1:], item)
+	n.children[firstRune].addItem(runes[1:], keyTerm, item, tokenPosition)
+}
+
+// addValue adds the given value to this node, along with the given token position if it is not negative.
+func (n *Node[T]) addValue(value T, tokenPosition int) {
+	n.values = append(n.values, value)
+	if tokenPosition >= 0 {
+		n.tokenPositions = append(n.tokenPositions, tokenPosition)
+	}
+}
+
+// getTokenPosition gets the token position of the value at the given index, which is zero for trees not loaded with a Tokenizer.
+func (n *Node[T]) getTokenPosition(valueIndex int) int {
+	if n.tokenPositions == nil {
+		return 0
+	}
+	return n.tokenPositions[valueIndex]
 }
 
 // getLeafNodes gets all leaf nodes that exist beneath this node
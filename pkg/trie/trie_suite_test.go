@@ -1,13 +1,50 @@
 package trie_test
 
 import (
+	"context"
+	_ "embed"
+	"github.com/coinbase/fuzzy-trie/pkg/trie"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
+//go:embed animals.txt
+var animalsText string
+
 func TestTrie(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Trie Suite")
 }
+
+// newTestContext builds a context with a timeout for the current spec, which is cancelled once the spec ends.
+func newTestContext() context.Context {
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	DeferCleanup(cancelFn)
+	return ctx
+}
+
+// textTermExtractor uses the text of a testComparableFuzzable as its key term.
+func textTermExtractor(_ context.Context, item *testComparableFuzzable) (string, error) {
+	return item.text, nil
+}
+
+// newTestAnimals builds an item for each line of animals.txt.
+func newTestAnimals() []*testComparableFuzzable {
+	animalNames := strings.Split(animalsText, "\n")
+	animals := make([]*testComparableFuzzable, len(animalNames))
+	for i, animalName := range animalNames {
+		animals[i] = newTestComparableFuzzable(animalName)
+	}
+	return animals
+}
+
+// loadTestTree loads a tree of the given items keyed by their text, failing the spec if it cannot be loaded.
+func loadTestTree(ctx context.Context, items []*testComparableFuzzable, options ...trie.LoadOption) *trie.Tree[*testComparableFuzzable] {
+	tree, err := trie.LoadTree(ctx, items, textTermExtractor, options...)
+	Expect(err).ToNot(HaveOccurred(), "loading the tree should not fail")
+	return tree
+}
@@ -6,16 +6,13 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"strings"
-	"time"
 )
 
 var _ = Describe("Trie", func() {
 	var ctx context.Context
 
 	BeforeEach(func() {
-		var cancelFn context.CancelFunc
-		ctx, cancelFn = context.WithTimeout(context.Background(), 5*time.Second)
-		DeferCleanup(cancelFn)
+		ctx = newTestContext()
 	})
 
 	Context("LoadTree", func() {
@@ -74,6 +71,225 @@ var _ = Describe("Trie", func() {
 			Expect(dogNode.GetKeyTerm()).To(Equal("DOG"), "the dog node should have the correct key term")
 		})
 	})
+
+	Context("traversal", func() {
+		var tree *trie.Tree[*testItem]
+
+		BeforeEach(func() {
+			var err error
+			tree, err = trie.LoadTree(ctx, []*testItem{{text: "cat"}, {text: "cataracts"}, {text: "car"}, {text: "dog"}}, func(_ context.Context, item *testItem) (string, error) {
+				return item.GetText(), nil
+			})
+			Expect(err).ToNot(HaveOccurred(), "loading the tree should not fail")
+		})
+
+		It("should expose the root and its children", func() {
+			root := tree.Root()
+			_, hasKeyRune := root.GetKeyRune()
+			Expect(hasKeyRune).To(BeFalse(), "the root should have no key rune")
+			Expect(root.GetKeyTerm()).To(BeEmpty(), "the root should have an empty key term")
+
+			var childRunes []rune
+			for _, childNode := range root.Children() {
+				childRune, _ := childNode.GetKeyRune()
+				childRunes = append(childRunes, childRune)
+			}
+			Expect(childRunes).To(Equal([]rune{'C', 'D'}), "the children should be ordered by their runes")
+			Expect(root.GetChild('C').GetChild('A').GetKeyTerm()).To(Equal("CA"), "children should be found by their runes")
+			Expect(root.GetChild('X')).To(BeNil(), "missing children should not be found")
+		})
+
+		It("should walk the tree depth-first", func() {
+			var keyTerms []string
+			Expect(tree.Walk(func(node *trie.Node[*testItem]) error {
+				if len(node.GetValues()) > 0 {
+					keyTerms = append(keyTerms, node.GetKeyTerm())
+				}
+				return nil
+			})).To(Succeed(), "walking the tree should not fail")
+			Expect(keyTerms).To(Equal([]string{"CAR", "CAT", "CATARACTS", "DOG"}), "the nodes should be visited in order")
+		})
+
+		It("should skip the descendants of a node", func() {
+			var keyTerms []string
+			Expect(tree.Walk(func(node *trie.Node[*testItem]) error {
+				keyTerms = append(keyTerms, node.GetKeyTerm())
+				if node.GetKeyTerm() == "CA" {
+					return trie.SkipChildren
+				}
+				return nil
+			})).To(Succeed(), "skipping children should not fail the walk")
+			Expect(keyTerms).To(Equal([]string{"", "C", "CA", "D", "DO", "DOG"}), "the descendants of the node should be skipped")
+		})
+
+		It("should match prepared queries against nodes", func() {
+			query := tree.PrepareQuery("ct")
+			Expect(query.Term()).To(Equal("CT"), "the query should be normalized")
+			Expect(tree.FindPrefix("cataracts").ContainsQuery(query)).To(BeTrue(), "the characters of the query should be found in order")
+			Expect(tree.FindPrefix("dog").ContainsQuery(query)).To(BeFalse(), "absent characters should not be found")
+		})
+
+		It("should find the node of a prefix", func() {
+			prefixNode := tree.FindPrefix("cat")
+			Expect(prefixNode).ToNot(BeNil(), "the prefix should be found")
+			Expect(prefixNode.GetKeyTerm()).To(Equal("CAT"), "the prefix should be normalized")
+			Expect(tree.FindPrefix("")).To(Equal(tree.Root()), "the empty prefix should be the root")
+			Expect(tree.FindPrefix("cow")).To(BeNil(), "a missing prefix should not be found")
+		})
+	})
+
+	Context("LoadTreeMulti", func() {
+		var bitcoin, ether *testComparableFuzzable
+		var tree *trie.Tree[*testComparableFuzzable]
+
+		BeforeEach(func() {
+			bitcoin = newTestComparableFuzzable("Bitcoin")
+			ether = newTestComparableFuzzable("Ether")
+			aliases := map[*testComparableFuzzable][]string{
+				bitcoin: {"Bitcoin", "BTC", "XBT", "btc"},
+				ether:   {"Ether", "ETH"},
+			}
+
+			var err error
+			tree, err = trie.LoadTreeMulti(ctx, []*testComparableFuzzable{bitcoin, ether}, func(_ context.Context, item *testComparableFuzzable) ([]string, error) {
+				return aliases[item], nil
+			})
+			Expect(err).ToNot(HaveOccurred(), "loading the tree should not fail")
+		})
+
+		It("should place each item under each of its distinct terms", func() {
+			var keyTerms []string
+			for _, leafNode := range tree.GetLeafNodes() {
+				keyTerms = append(keyTerms, leafNode.GetKeyTerm())
+				Expect(leafNode.GetValues()).To(HaveLen(1), "an item should be placed once under terms that normalize identically")
+			}
+			Expect(keyTerms).To(ConsistOf("BITCOIN", "BTC", "XBT", "ETHER"), "each term should be placed in the tree")
+		})
+
+		It("should return each item once at its closest distance across its terms", func() {
+			results, err := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{tree}).SearchWithOptions(ctx, "xbt", trie.SearchOptions{})
+			Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+			Expect(results).To(HaveLen(1), "the item should be returned once")
+			Expect(results[0].Result).To(Equal(bitcoin), "the item should be matched by its alias")
+			Expect(results[0].Matches[0].Distance).To(BeZero(), "the closest alias should determine the distance")
+			Expect(results[0].Matches[0].KeyTerm).To(Equal("XBT"), "the closest alias should be the match")
+
+			results, err = trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{tree}).SearchWithOptions(ctx, "t", trie.SearchOptions{})
+			Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+			Expect(results).To(HaveLen(2), "each item should be returned once")
+			Expect(results[0].Result).To(Equal(ether), "the item with the shorter text should break the tie")
+			Expect(results[0].Matches[0].KeyTerm).To(Equal("ETH"), "the closest alias should be the match")
+			Expect(results[1].Matches[0].Distance).To(BeNumerically("~", 2), "the closest alias should determine the distance")
+			Expect(results[1].Matches[0].KeyTerm).To(Equal("BTC"), "equally-close aliases should be chosen consistently")
+		})
+
+		It("should remove an item from under each of its terms", func() {
+			Expect(tree.Remove(ctx, bitcoin)).To(Succeed(), "removing the item should not fail")
+			Expect(tree.GetLeafNodes()).To(HaveLen(1), "only the other item's branch should remain")
+			Expect(tree.GetLeafNodes()[0].GetKeyTerm()).To(Equal("ETHER"), "only the other item's branch should remain")
+
+			Expect(tree.Insert(ctx, bitcoin)).To(Succeed(), "re-inserting the item should not fail")
+			Expect(tree.GetLeafNodes()).To(HaveLen(4), "the item should be placed under each of its terms")
+		})
+	})
+
+	Context("mutation", func() {
+		var animals []*testComparableFuzzable
+
+		// searchTexts searches a fresh DistanceTrees over the given tree, returning the texts of the results in order
+		searchTexts := func(tree *trie.Tree[*testComparableFuzzable], searchTerm string) []string {
+			results, err := trie.NewDistanceTrees([]*trie.Tree[*testComparableFuzzable]{tree}).Search(ctx, searchTerm)
+			Expect(err).ToNot(HaveOccurred(), "searching the tree should not fail")
+			texts := make([]string, len(results))
+			for i, result := range results {
+				texts[i] = result.Result.text
+			}
+			return texts
+		}
+
+		// leafKeyTerms gets the key terms of all the leaf nodes in the given tree
+		leafKeyTerms := func(tree *trie.Tree[*testComparableFuzzable]) []string {
+			var keyTerms []string
+			for _, leafNode := range tree.GetLeafNodes() {
+				keyTerms = append(keyTerms, leafNode.GetKeyTerm())
+			}
+			return keyTerms
+		}
+
+		BeforeEach(func() {
+			animals = newTestAnimals()
+		})
+
+		It("should produce the same search results as a freshly-loaded tree after inserting items", func() {
+			tree := loadTestTree(ctx, animals[:len(animals)/2])
+			for _, animal := range animals[len(animals)/2:] {
+				Expect(tree.Insert(ctx, animal)).To(Succeed(), "inserting '%s' should not fail", animal.text)
+			}
+
+			freshTree := loadTestTree(ctx, animals)
+
+			Expect(leafKeyTerms(tree)).To(ConsistOf(leafKeyTerms(freshTree)), "the leaf nodes should match the fresh tree")
+			for _, searchTerm := range []string{"cat", "wol", "e", "domestic"} {
+				Expect(searchTexts(tree, searchTerm)).To(Equal(searchTexts(freshTree, searchTerm)), "the results for '%s' should match the fresh tree", searchTerm)
+			}
+		})
+
+		It("should produce the same search results as a freshly-loaded tree after removing items", func() {
+			tree := loadTestTree(ctx, animals)
+
+			var remainingAnimals []*testComparableFuzzable
+			for i, animal := range animals {
+				if i%3 == 0 {
+					Expect(tree.Remove(ctx, animal)).To(Succeed(), "removing '%s' should not fail", animal.text)
+				} else {
+					remainingAnimals = append(remainingAnimals, animal)
+				}
+			}
+
+			freshTree := loadTestTree(ctx, remainingAnimals)
+
+			Expect(leafKeyTerms(tree)).To(ConsistOf(leafKeyTerms(freshTree)), "the leaf nodes should match the fresh tree")
+			for _, searchTerm := range []string{"cat", "wol", "e", "domestic"} {
+				Expect(searchTexts(tree, searchTerm)).To(Equal(searchTexts(freshTree, searchTerm)), "the results for '%s' should match the fresh tree", searchTerm)
+			}
+		})
+
+		It("should prune empty branches and promote former interior nodes to leaves", func() {
+			cat := newTestComparableFuzzable("cat")
+			cataracts := newTestComparableFuzzable("cataracts")
+			tree := loadTestTree(ctx, []*testComparableFuzzable{cat, cataracts})
+			Expect(leafKeyTerms(tree)).To(ConsistOf("CATARACTS"), "only the cataracts node should be a leaf")
+
+			Expect(tree.Remove(ctx, cataracts)).To(Succeed(), "removing cataracts should not fail")
+			Expect(leafKeyTerms(tree)).To(ConsistOf("CAT"), "the cat node should have been promoted to a leaf")
+			Expect(tree.GetLeafNodes()[0].GetValues()).To(ConsistOf(cat), "the cat node should retain its value")
+
+			Expect(tree.Remove(ctx, cat)).To(Succeed(), "removing cat should not fail")
+			Expect(leafKeyTerms(tree)).To(ConsistOf(""), "only the root node should remain")
+			Expect(searchTexts(tree, "cat")).To(BeEmpty(), "an emptied tree should not return results")
+
+			Expect(tree.Insert(ctx, cataracts)).To(Succeed(), "re-inserting cataracts should not fail")
+			Expect(leafKeyTerms(tree)).To(ConsistOf("CATARACTS"), "the cataracts node should be the only leaf")
+			Expect(searchTexts(tree, "cat")).To(Equal([]string{"cataracts"}), "the re-inserted item should be searchable")
+		})
+
+		It("should fail to remove an item that is not in the tree", func() {
+			tree := loadTestTree(ctx, animals)
+
+			Expect(tree.Remove(ctx, newTestComparableFuzzable("Unicorn"))).To(MatchError(trie.ErrItemNotFound), "a term absent from the tree should not be found")
+			Expect(tree.Remove(ctx, newTestComparableFuzzable("Cat"))).To(MatchError(trie.ErrItemNotFound), "an item absent from its term's node should not be found")
+		})
+
+		It("should re-place an updated item", func() {
+			wolf := newTestComparableFuzzable("Wolf")
+			tree := loadTestTree(ctx, []*testComparableFuzzable{wolf})
+
+			werewolf := newTestComparableFuzzable("Werewolf")
+			Expect(tree.Update(ctx, wolf, werewolf)).To(Succeed(), "updating the wolf should not fail")
+			Expect(leafKeyTerms(tree)).To(ConsistOf("WEREWOLF"), "the old branch should have been pruned")
+			Expect(searchTexts(tree, "wolf")).To(Equal([]string{"Werewolf"}), "only the new item should be found")
+		})
+	})
 })
 
 type testItem struct {
@@ -0,0 +1,88 @@
+package trie
+
+import (
+	"fmt"
+	"math"
+)
+
+// WeightedTree configures how a Tree participates in a search of a DistanceTrees instance.
+type WeightedTree[T any] struct {
+	// Tree is the tree to be searched.
+	Tree *Tree[T]
+	// Weight scales the contribution of a match in this tree to the Score of a DistanceResult.
+	// A weight of zero means that matches in this tree do not contribute to the score at all.
+	Weight float64
+	// MaxDistance, if set, is the largest distance a key term in this tree may be from the search term to be matched.
+	// If a maximum distance is also given in the SearchOptions of a search, the smaller of the two limits is applied.
+	MaxDistance *int
+	// Scorer converts the distance of a match in this tree to a score; if not set, InverseDistanceScorer is used.
+	Scorer Scorer
+	// Metric measures the distances of key terms in this tree from the search term; if not set, the Metric of the
+	// DistanceTrees instance is used.
+	Metric Metric
+	// distanceOffset is added to the distances of matches in this tree within the Distances of a DistanceResult
+	distanceOffset int
+}
+
+// Scorer converts the distance of a key term from the search term into a score, where a higher score denotes a closer match.
+type Scorer func(distance float64) float64
+
+// InverseDistanceScorer scores an exact match as 1, decaying towards 0 as the distance increases.
+func InverseDistanceScorer(distance float64) float64 {
+	return 1 / (1 + distance)
+}
+
+// NewDistanceTreesWithWeights builds a DistanceTrees instance that ranks results by their combined Score across the
+// given trees, falling back to their per-tree distances to break ties.
+// Unlike NewDistanceTrees, the Distances of its results are not offset by the index of each tree.
+// This returns an error if any of the given trees, or the Tree of any of them, is nil.
+func NewDistanceTreesWithWeights[T ComparableFuzzable](trees []*WeightedTree[T]) (*DistanceTrees[T], error) {
+	weightedTrees := make([]*WeightedTree[T], len(trees))
+	for treeIndex, tree := range trees {
+		if tree == nil || tree.Tree == nil {
+			return nil, fmt.Errorf("no tree was given at index %d", treeIndex)
+		}
+
+		weightedTree := *tree
+		if weightedTree.Scorer == nil {
+			weightedTree.Scorer = InverseDistanceScorer
+		}
+		weightedTree.distanceOffset = 0
+		weightedTrees[treeIndex] = &weightedTree
+	}
+
+	return &DistanceTrees[T]{
+		trees:       weightedTrees,
+		timer:       defaultTimer,
+		rankByScore: true,
+		metric:      LevenshteinMetric,
+	}, nil
+}
+
+// getMaxDistance combines the given search-wide maximum distance, which is -1 if there is no limit, with this tree's
+// maximum distance, returning the smaller of the two or -1 if neither imposes a limit.
+func (wt *WeightedTree[T]) getMaxDistance(searchMaxDistance int) int {
+	if wt.MaxDistance == nil {
+		return searchMaxDistance
+	} else if searchMaxDistance < 0 {
+		return *wt.MaxDistance
+	}
+	return min(searchMaxDistance, *wt.MaxDistance)
+}
+
+// score scores the given distance of a match in this tree, applying the tree's weight.
+func (wt *WeightedTree[T]) score(distance float64) float64 {
+	return wt.Weight * wt.Scorer(distance)
+}
+
+// getDefaultDistanceOffset gets the offset of the distances of the tree at the given index for a DistanceTrees
+// instance built without explicit weights, 10^treeIndex, as DistanceTrees has always offset them.
+func getDefaultDistanceOffset(treeIndex int) int {
+	return int(math.Pow(10, float64(treeIndex)))
+}
+
+// getDefaultWeight gets the weight of the tree at the given index for a DistanceTrees instance built without explicit
+// weights, making each tree a tenth as significant as the tree before it.
+func getDefaultWeight(treeIndex int) float64 {
+	return math.Pow(10, -float64(treeIndex))
+}